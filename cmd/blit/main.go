@@ -1,9 +1,13 @@
 package main
 
 import (
+	"embed"
 	"fmt"
 	"image/color"
+	"image/png"
 	"log"
+	"math"
+	"os"
 	"runtime"
 	"time"
 
@@ -12,6 +16,9 @@ import (
 	"github.com/octalide/wisp/pkg/wisp"
 )
 
+//go:embed tiles
+var assets embed.FS
+
 func main() {
 	runtime.LockOSThread()
 
@@ -38,10 +45,12 @@ func main() {
 
 	// load spritesheet
 	log.Println("loading spritesheet...")
-	ss, err := blit.LoadSpritesheet("tiles/ground.png", "tiles/ground.json")
-	if err != nil {
+	res := blit.NewAssets(assets)
+	sheet := res.LoadSpritesheet("tiles/ground.png", "tiles/ground.json")
+	if err := res.Wait(sheet); err != nil {
 		panic(err)
 	}
+	ss := sheet.Spritesheet()
 
 	log.Println("creating shader...")
 	shader, err := bgl.DefaultProgram()
@@ -49,6 +58,37 @@ func main() {
 		panic(err)
 	}
 
+	batchShader, err := bgl.BatchProgram()
+	if err != nil {
+		panic(err)
+	}
+
+	// post-processing, number keys toggle the effects
+	log.Println("creating effects...")
+	fx, err := blit.NewPostFX(win.GetWidth(), win.GetHeight())
+	if err != nil {
+		panic(err)
+	}
+	fx.Listen()
+
+	lut := bgl.NewTexture(blit.NeutralLUT(16), bgl.Linear)
+	for _, fn := range []func() (*blit.Effect, error){
+		func() (*blit.Effect, error) { return blit.NewBloom(0.8, 1) },
+		func() (*blit.Effect, error) { return blit.NewBlur(1) },
+		blit.NewGrayscale,
+		func() (*blit.Effect, error) { return blit.NewColorGrade(lut, 16) },
+		blit.NewVignette,
+		blit.NewCRT,
+	} {
+		e, err := fn()
+		if err != nil {
+			panic(err)
+		}
+
+		e.Enabled = false
+		fx.Add(e)
+	}
+
 	log.Println("creating sprites...")
 	dirt, err := ss.Get("dirt", shader)
 	if err != nil {
@@ -65,43 +105,82 @@ func main() {
 		panic(err)
 	}
 
-	var minFOV, maxFOV, zoomFactor float32
-	minFOV = 170
-	maxFOV = 179.5
-	zoomFactor = 0.4
+	// grass orbits the spinning dirt, stone orbits the grass
+	scene := blit.NewNode(nil)
+	dirtNode := blit.NewNode(dirt)
+	grassNode := blit.NewNode(grass)
+	grassNode.SetPos(blit.Vec{2, 0, 0})
+	stoneNode := blit.NewNode(stone)
+	stoneNode.SetPos(blit.Vec{1, 0, 0})
+	stoneNode.SetScale(blit.Vec{0.5, 0.5, 1})
+
+	if err := scene.Add(dirtNode); err != nil {
+		panic(err)
+	}
+	if err := dirtNode.Add(grassNode); err != nil {
+		panic(err)
+	}
+	if err := grassNode.Add(stoneNode); err != nil {
+		panic(err)
+	}
+
+	// the spinning dirt cycles through the tiles
+	cycle := &blit.Animation{Name: "cycle", Mode: blit.AnimPingPong}
+	for _, name := range []string{"dirt", "grass", "stone"} {
+		cycle.Frames = append(cycle.Frames, blit.Frame{Rect: ss.Sprites[name], Duration: 500 * time.Millisecond})
+	}
+	anim := blit.NewAnimPlayer(cycle, dirt)
+
+	// tile floor drawn through a batch
+	tiles := []string{"dirt", "grass", "stone"}
+	placed := map[[2]int]string{}
+	floor := blit.NewBatch()
+
+	// 16x16 pixel tiles are one world unit
+	cam := blit.NewOrthoCam(1.0 / 16)
+	cam.SetZoom(2)
+	cam.MaxZoom = 8
+
+	// minimap showing the whole floor in the top right corner
+	minimap := blit.NewOrthoCam(1.0 / 4)
+	minimapSize := 144
+
+	// drag with the middle mouse button, zoom towards the cursor and keep the
+	// view over the floor
+	ctl := blit.NewCamController(&cam)
+	ctl.Bounds = blit.Rect{-16.5, -16.5, 32, 32}
+	ctl.Listen(win)
 
-	cam := blit.NewCam()
-	cam.FOV = minFOV + (maxFOV-minFOV)/2
-	cam.Viewport = blit.Viewport()
+	// F12 saves a screenshot of the main camera rendered offscreen
+	screenshot := false
 
-	// camera controller
 	wisp.AddHandler(&wisp.Handler{
 		Callback: func(e *wisp.Event) bool {
 			switch e.Tag {
-			case "core.input.mouse.scroll":
-				delta := e.Data.(blit.Vec).Y()
-				cam.FOV -= delta * zoomFactor
-				if cam.FOV < minFOV {
-					cam.FOV = minFOV
+			case "core.input.key.down":
+				key := e.Data.(blit.Key)
+				if key == blit.KeyF12 {
+					screenshot = true
 				}
-				if cam.FOV > maxFOV {
-					cam.FOV = maxFOV
+				if i := int(key - blit.Key1); i >= 0 && i < len(fx.Effects) {
+					effect := fx.Effects[i]
+					effect.Enabled = !effect.Enabled
+					log.Printf("%v: %v", effect.Name, effect.Enabled)
 				}
 			case "core.input.mouse.move":
-				// change camera position by mouse delta if middle mouse button is down
-				delta := blit.MouseDelta()
-				// invert Y axis
-				delta[1] *= -1
-
-				// change delta to world coordinates
-				// delta = cam.Unproject(delta)
-				pan := delta.Scl(0.01 * (cam.FOV - minFOV))
-
-				if blit.Keys(blit.MouseButtonMiddle) {
-					cam.Pan(pan.Inv())
+				world, _ := cam.Pick(win.Screen(blit.MousePos()), 0)
+				fmt.Printf("%v : %v %v\r", cam.Zoom, cam.Vec, world)
+			case "core.input.mouse.button.down":
+				// place a stone tile under the cursor
+				if e.Data.(blit.Key) == blit.MouseButtonLeft {
+					world, ok := cam.Pick(win.Screen(blit.MousePos()), -1)
+					if ok {
+						x := int(math.Floor(float64(world.X()) + 0.5))
+						y := int(math.Floor(float64(world.Y()) + 0.5))
+						placed[[2]int{x, y}] = "stone"
+						ctl.Shake.Add(0.3)
+					}
 				}
-
-				fmt.Printf("%v : %v %v %v\r", cam.FOV, cam.Vec, delta, pan)
 			}
 			// log.Printf("event (%v): %v", e.Tag, e.Data)
 			return false
@@ -110,6 +189,23 @@ func main() {
 		Blocking: false,
 	})
 
+	// draws the floor and scene, the camera is applied by Cam.Render
+	draw := func() {
+		for x := -16; x < 16; x++ {
+			for y := -16; y < 16; y++ {
+				name, ok := placed[[2]int{x, y}]
+				if !ok {
+					name = tiles[(x*x+y*y)%len(tiles)]
+				}
+				m := blit.Ident().Pos(blit.Vec{float32(x), float32(y), -1})
+				floor.AddQuad(batchShader, ss.Texture, ss.Sprites[name], m, color.RGBA{255, 255, 255, 255})
+			}
+		}
+		floor.Flush()
+
+		scene.Draw()
+	}
+
 	log.Println("entering main loop...")
 
 	lastPrint := time.Now()
@@ -118,17 +214,40 @@ func main() {
 		delta := time.Since(last)
 		last = time.Now()
 
-		dirt.O.R += float32(0.2 * delta.Seconds())
-		grass.O.Vec[0] += float32(0.2 * delta.Seconds())
-		stone.O.Vec[1] -= float32(0.2 * delta.Seconds())
-		// fmt.Printf("%.3f : %v\r", dirt.O.R, dirt.O.Mat())
+		dirtNode.SetRot(dirtNode.O.R + float32(0.2*delta.Seconds()))
+		grassNode.SetRot(grassNode.O.R - float32(0.6*delta.Seconds()))
+
+		ctl.Update(delta)
+		anim.Update(delta)
+
+		fx.Begin()
+		cam.Render(func() {
+			draw()
+		})
+		fx.End()
+
+		minimap.Viewport = blit.Rect{
+			float32(win.GetWidth() - minimapSize - 8),
+			float32(win.GetHeight() - minimapSize - 8),
+			float32(minimapSize),
+			float32(minimapSize),
+		}
+		minimap.Render(func() {
+			bgl.Clear()
+			draw()
+		})
 
-		cam.Use(shader)
+		if screenshot {
+			screenshot = false
 
-		bgl.Clear()
-		dirt.Draw()
-		grass.Draw()
-		stone.Draw()
+			if err := capture(win.GetWidth(), win.GetHeight(), "screenshot.png", func() {
+				cam.Render(draw)
+			}); err != nil {
+				log.Printf("screenshot failed: %v", err)
+			} else {
+				log.Println("saved screenshot.png")
+			}
+		}
 
 		blit.Update()
 
@@ -138,3 +257,35 @@ func main() {
 		}
 	}
 }
+
+// capture renders fn into an offscreen framebuffer of the given size and saves
+// the result as a PNG file
+func capture(w, h int, path string, fn func()) error {
+	tex := bgl.NewTextureFormat(w, h, bgl.FormatRGBA8, bgl.Nearest)
+	defer tex.Delete()
+
+	depth := bgl.NewRenderbuffer(w, h, bgl.FormatDepth24, 0)
+	defer depth.Delete()
+
+	fb := bgl.NewFramebuffer(w, h)
+	defer fb.Delete()
+
+	fb.AttachTexture(bgl.ColorAttachment(0), tex)
+	fb.AttachRenderbuffer(bgl.DepthAttachment, depth)
+	if err := fb.Check(); err != nil {
+		return err
+	}
+
+	fb.Bind()
+	bgl.Clear()
+	fn()
+	fb.Unbind()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, fb.ReadImage(0))
+}
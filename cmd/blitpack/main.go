@@ -0,0 +1,114 @@
+// Command blitpack packs a folder of PNG images into atlas pages.
+//
+// Each page is written as a PNG image and a JSON descriptor readable by
+// blit.LoadSpritesheet. A single page is written to <out>.png and <out>.json,
+// multiple pages to <out>_0.png, <out>_0.json, <out>_1.png and so on. Sprites
+// are named by their path relative to the input folder without the extension.
+//
+// blitpack does not open a window or create an OpenGL context.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/octalide/blit/pkg/atlas"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("blitpack: ")
+
+	opt := atlas.DefaultOptions()
+
+	in := flag.String("in", ".", "folder containing the PNG images to pack")
+	out := flag.String("out", "atlas", "output path without extension")
+	flag.IntVar(&opt.MaxW, "max-width", opt.MaxW, "maximum page width in pixels")
+	flag.IntVar(&opt.MaxH, "max-height", opt.MaxH, "maximum page height in pixels")
+	flag.IntVar(&opt.Padding, "padding", opt.Padding, "transparent pixels between sprites")
+	flag.IntVar(&opt.Extrude, "extrude", opt.Extrude, "pixels the sprite edges are repeated outwards")
+	flag.BoolVar(&opt.Trim, "trim", opt.Trim, "remove fully transparent sprite borders")
+	flag.BoolVar(&opt.PowerOfTwo, "pot", opt.PowerOfTwo, "round page sizes up to powers of two")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		log.Fatalf("unexpected arguments: %v", flag.Args())
+	}
+
+	if err := pack(*in, *out, opt); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func pack(in, out string, opt atlas.Options) error {
+	info, err := os.Stat(in)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("input is not a folder: %v", in)
+	}
+
+	a := atlas.New(opt)
+	if err := a.AddFS(os.DirFS(in), "."); err != nil {
+		return err
+	}
+
+	if a.Len() == 0 {
+		return fmt.Errorf("no PNG images found in %v", in)
+	}
+
+	pages, err := a.Pack()
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(out); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	for i, page := range pages {
+		name := out
+		if len(pages) > 1 {
+			name = fmt.Sprintf("%v_%v", out, i)
+		}
+
+		if err := writePage(page, name); err != nil {
+			return err
+		}
+
+		b := page.Image.Bounds()
+		log.Printf("%v.png: %v sprites, %vx%v", name, len(page.Sprites), b.Dx(), b.Dy())
+	}
+
+	return nil
+}
+
+func writePage(page *atlas.Page, name string) error {
+	f, err := os.Create(name + ".png")
+	if err != nil {
+		return err
+	}
+
+	if err := page.WritePNG(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %v.png: %w", name, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	desc, err := page.Descriptor()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(name+".json", desc, 0644)
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
-	"fmt"
+	"image"
 	"image/color"
 	"log"
+	"math"
 	"runtime"
 	"time"
 
@@ -36,74 +37,114 @@ func main() {
 	bgl.SetClearColor(color.RGBA{0, 0, 255, 255})
 	bgl.SetBounds(0, 0, win.GetWidth(), win.GetHeight())
 
+	bgl.EnableDepthTest()
+
 	log.Println("creating shader...")
-	shader, err := bgl.DefaultProgram()
+	shader, err := bgl.BatchProgram()
 	if err != nil {
 		panic(err)
 	}
 
-	var minFOV, maxFOV, zoomFactor float32
-	minFOV = 170
-	maxFOV = 179.5
-	zoomFactor = 0.4
+	// two 16x16 tiles: grass top and dirt side
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	for x := 0; x < 32; x++ {
+		for y := 0; y < 16; y++ {
+			n := uint8((x*7 + y*13) % 5 * 8)
+			if x < 16 || y < 3 {
+				img.SetRGBA(x, y, color.RGBA{40 + n, 150 + n, 40, 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{110 + n, 70 + n, 30, 255})
+			}
+		}
+	}
+	tex := bgl.NewTexture(img, bgl.Nearest)
+	top := blit.Rect{0, 0, 16, 16}
+	side := blit.Rect{16, 0, 16, 16}
+
+	// build a heightmap of voxel columns
+	const size = 32
+	var heights [size][size]int
+	for x := 0; x < size; x++ {
+		for z := 0; z < size; z++ {
+			h := 3 + 2*math.Sin(float64(x)/4) + 2*math.Cos(float64(z)/5)
+			heights[x][z] = int(math.Round(h))
+		}
+	}
+
+	terrain := blit.NewBatch()
+	white := color.RGBA{255, 255, 255, 255}
+	column := func(x, z float32, h float32) {
+		terrain.AddQuad(shader, tex, top, blit.Ident().Pos(blit.Vec{x, h, z}).RotX(-math.Pi/2), white)
+
+		for i := 0; i < 4; i++ {
+			rot := float32(i) * math.Pi / 2
+			m := blit.Ident().
+				Pos(blit.Vec{x, h / 2, z}).
+				RotY(rot).
+				Pos(blit.Vec{0, 0, 0.5}).
+				Scale(blit.Vec{1, h, 1})
+
+			terrain.AddQuad(shader, tex, side, m, white)
+		}
+	}
+
+	// the terrain is static, build it once and draw it every frame
+	for x := 0; x < size; x++ {
+		for z := 0; z < size; z++ {
+			column(float32(x-size/2), float32(z-size/2), float32(heights[x][z]))
+		}
+	}
 
 	cam := blit.NewCam()
-	cam.FOV = minFOV + (maxFOV-minFOV)/2
-	cam.Viewport = blit.Viewport()
+	cam.Vec = blit.Vec{0, 12, 24}
+	cam.Pitch(-0.4)
+
+	// tab switches between flying (WASD, mouse look) and orbiting
+	fly := blit.NewFlyController(&cam)
+	fly.Listen(win)
+
+	orbit := blit.NewOrbitController(&cam, 24)
+	orbit.Enabled = false
+	orbit.Listen(win)
 
-	// camera controller
 	wisp.AddHandler(&wisp.Handler{
 		Callback: func(e *wisp.Event) bool {
-			switch e.Tag {
-			case "core.input.mouse.scroll":
-				delta := e.Data.(blit.Vec).Y()
-				cam.FOV -= delta * zoomFactor
-				if cam.FOV < minFOV {
-					cam.FOV = minFOV
-				}
-				if cam.FOV > maxFOV {
-					cam.FOV = maxFOV
-				}
-			case "core.input.mouse.move":
-				// change camera position by mouse delta if middle mouse button is down
-				delta := blit.MouseDelta()
-				// invert Y axis
-				delta[1] *= -1
-
-				// change delta to world coordinates
-				// delta = cam.Unproject(delta)
-				pan := delta.Scl(0.01 * (cam.FOV - minFOV))
-
-				if blit.Keys(blit.MouseButtonMiddle) {
-					cam.Pan(pan.Inv())
-				}
-
-				fmt.Printf("%v : %v %v %v\r", cam.FOV, cam.Vec, delta, pan)
+			if e.Data.(blit.Key) != blit.KeyTab {
+				return false
+			}
+
+			if fly.Enabled {
+				fly.Release(win)
+				fly.Enabled = false
+				orbit.Enabled = true
+				orbit.Sync()
+			} else {
+				orbit.Enabled = false
+				fly.Enabled = true
+				fly.Sync()
 			}
-			// log.Printf("event (%v): %v", e.Tag, e.Data)
+
 			return false
 		},
-		Tags:     []string{"*"},
+		Tags:     []string{"core.input.key.down"},
 		Blocking: false,
 	})
 
 	log.Println("entering main loop...")
 
-	lastPrint := time.Now()
 	last := time.Now()
 	for !win.ShouldClose() {
-		time.Since(last) // delta
+		delta := time.Since(last)
 		last = time.Now()
 
-		cam.Use(shader)
+		fly.Update(delta)
+
+		cam.Apply()
 
 		bgl.Clear()
 
-		blit.Update()
+		terrain.Draw()
 
-		if time.Since(lastPrint) > time.Second {
-			// fmt.Printf("fps: %v\r", float64(1/delta.Seconds()))
-			lastPrint = time.Now()
-		}
+		blit.Update()
 	}
 }
@@ -10,6 +10,5 @@ require (
 require (
 	github.com/go-gl/mathgl v1.0.0
 	github.com/octalide/wisp v0.0.0-20211103062820-b338fda73b1c
+	golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f
 )
-
-require golang.org/x/image v0.0.0-20190321063152-3fc05d484e9f // indirect
@@ -0,0 +1,340 @@
+// Package atlas packs images into texture atlas pages. It does not depend on
+// OpenGL, so tools such as blitpack can run it headless.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Options configures the packing of an Atlas
+type Options struct {
+	MaxW, MaxH int  // maximum page size in pixels
+	Padding    int  // transparent pixels between sprites
+	Extrude    int  // pixels the sprite edges are repeated outwards
+	Trim       bool // remove fully transparent borders from sprites
+	PowerOfTwo bool // round page sizes up to powers of two
+}
+
+// DefaultOptions returns options for 2048x2048 pages with 1 pixel of padding
+// and no extrusion or trimming
+func DefaultOptions() Options {
+	return Options{
+		MaxW:    2048,
+		MaxH:    2048,
+		Padding: 1,
+	}
+}
+
+// Atlas packs many images into one or more atlas pages
+type Atlas struct {
+	Options
+
+	names  []string
+	images map[string]image.Image
+}
+
+// Page is a packed atlas image and the sprites on it
+type Page struct {
+	Image   *image.RGBA
+	Sprites map[string]image.Rectangle
+	Trims   map[string]Trim
+}
+
+// Trim describes where a trimmed sprite lies in its untrimmed source image
+type Trim struct {
+	Offset image.Point // position of the trimmed area in the source image
+	Size   image.Point // size of the untrimmed source image
+}
+
+// New creates an empty atlas
+func New(opt Options) *Atlas {
+	return &Atlas{
+		Options: opt,
+		images:  map[string]image.Image{},
+	}
+}
+
+// Add adds a named image to the atlas
+func (a *Atlas) Add(name string, img image.Image) error {
+	if _, ok := a.images[name]; ok {
+		return fmt.Errorf("duplicate atlas sprite: \"%v\"", name)
+	}
+
+	a.names = append(a.names, name)
+	a.images[name] = img
+
+	return nil
+}
+
+// AddFS adds all PNG images below dir in fsys. Sprites are named by their path
+// relative to dir without the extension.
+func (a *Atlas) AddFS(fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || strings.ToLower(path.Ext(p)) != ".png" {
+			return nil
+		}
+
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		img, err := png.Decode(f)
+		if err != nil {
+			return fmt.Errorf("failed to decode %v: %w", p, err)
+		}
+
+		name := p
+		if dir != "." {
+			name = strings.TrimPrefix(p, dir+"/")
+		}
+		name = strings.TrimSuffix(name, path.Ext(name))
+
+		return a.Add(name, img)
+	})
+}
+
+// Len returns the number of images in the atlas
+func (a *Atlas) Len() int {
+	return len(a.names)
+}
+
+// item is an image waiting to be placed
+type item struct {
+	name string
+	img  image.Image
+	src  image.Rectangle // area of img that is packed
+	trim Trim
+	w, h int // packed size including padding and extrusion
+}
+
+// Pack packs all images into as few pages as possible. It fails if an image
+// does not fit in a page of the maximum size.
+func (a *Atlas) Pack() ([]*Page, error) {
+	if a.MaxW <= 0 || a.MaxH <= 0 {
+		return nil, fmt.Errorf("invalid atlas page size: %vx%v", a.MaxW, a.MaxH)
+	}
+	if a.Padding < 0 || a.Extrude < 0 {
+		return nil, fmt.Errorf("invalid atlas padding or extrusion: %v, %v", a.Padding, a.Extrude)
+	}
+
+	maxW, maxH := a.MaxW, a.MaxH
+	if a.PowerOfTwo {
+		// rounding the page up must not exceed the maximum size
+		maxW = nextPowerOfTwo(maxW+1) / 2
+		maxH = nextPowerOfTwo(maxH+1) / 2
+	}
+
+	items := make([]*item, 0, len(a.names))
+	for _, name := range a.names {
+		img := a.images[name]
+
+		it := &item{
+			name: name,
+			img:  img,
+			src:  img.Bounds(),
+		}
+
+		if a.Trim {
+			it.src = opaqueBounds(img)
+			if it.src != img.Bounds() {
+				b := img.Bounds()
+				it.trim = Trim{
+					Offset: it.src.Min.Sub(b.Min),
+					Size:   b.Size(),
+				}
+			}
+		}
+
+		it.w = it.src.Dx() + 2*a.Extrude + a.Padding
+		it.h = it.src.Dy() + 2*a.Extrude + a.Padding
+
+		if it.w-a.Padding > maxW || it.h-a.Padding > maxH {
+			return nil, fmt.Errorf("sprite \"%v\" (%vx%v) does not fit in a %vx%v atlas page", name, it.src.Dx(), it.src.Dy(), maxW, maxH)
+		}
+
+		items = append(items, it)
+	}
+
+	// large items first, by name for stable output
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].h != items[j].h {
+			return items[i].h > items[j].h
+		}
+		if items[i].w != items[j].w {
+			return items[i].w > items[j].w
+		}
+		return items[i].name < items[j].name
+	})
+
+	var pages []*Page
+	for len(items) > 0 {
+		// the trailing padding of items on the right and bottom edges may
+		// overhang the page
+		bin := newMaxRects(maxW+a.Padding, maxH+a.Padding)
+
+		var placed []*item
+		var pos []image.Point
+		var rest []*item
+		for _, it := range items {
+			p, ok := bin.insert(it.w, it.h)
+			if !ok {
+				rest = append(rest, it)
+				continue
+			}
+
+			placed = append(placed, it)
+			pos = append(pos, p)
+		}
+
+		pages = append(pages, a.render(placed, pos))
+		items = rest
+	}
+
+	return pages, nil
+}
+
+// render draws placed items into a new page
+func (a *Atlas) render(items []*item, pos []image.Point) *Page {
+	w, h := 1, 1
+	for i, it := range items {
+		if r := pos[i].X + it.w - a.Padding; r > w {
+			w = r
+		}
+		if b := pos[i].Y + it.h - a.Padding; b > h {
+			h = b
+		}
+	}
+
+	if a.PowerOfTwo {
+		w = nextPowerOfTwo(w)
+		h = nextPowerOfTwo(h)
+	}
+
+	page := &Page{
+		Image:   image.NewRGBA(image.Rect(0, 0, w, h)),
+		Sprites: map[string]image.Rectangle{},
+		Trims:   map[string]Trim{},
+	}
+
+	for i, it := range items {
+		e := a.Extrude
+		dst := image.Rect(0, 0, it.src.Dx(), it.src.Dy()).Add(pos[i]).Add(image.Pt(e, e))
+
+		draw.Draw(page.Image, dst, it.img, it.src.Min, draw.Src)
+		extrude(page.Image, dst, e)
+
+		page.Sprites[it.name] = dst
+
+		if it.trim != (Trim{}) {
+			page.Trims[it.name] = it.trim
+		}
+	}
+
+	return page
+}
+
+// Descriptor returns the page description in the JSON format read by
+// blit.LoadSpritesheet
+func (p *Page) Descriptor() ([]byte, error) {
+	desc := map[string][]int{}
+	for name, r := range p.Sprites {
+		v := []int{r.Min.X, r.Min.Y, r.Dx(), r.Dy()}
+		if t, ok := p.Trims[name]; ok {
+			v = append(v, t.Offset.X, t.Offset.Y, t.Size.X, t.Size.Y)
+		}
+
+		desc[name] = v
+	}
+
+	return json.MarshalIndent(desc, "", "    ")
+}
+
+// WritePNG encodes the page image as PNG
+func (p *Page) WritePNG(w io.Writer) error {
+	return png.Encode(w, p.Image)
+}
+
+// opaqueBounds returns the smallest rectangle containing all pixels of img
+// that are not fully transparent. Fully transparent images keep a single
+// pixel.
+func opaqueBounds(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	r := image.Rectangle{}
+	found := false
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+
+			p := image.Rect(x, y, x+1, y+1)
+			if !found {
+				r = p
+				found = true
+			} else {
+				r = r.Union(p)
+			}
+		}
+	}
+
+	if !found {
+		return image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+	}
+
+	return r
+}
+
+// extrude repeats the edge pixels of r outwards by n pixels
+func extrude(img *image.RGBA, r image.Rectangle, n int) {
+	if n <= 0 || r.Empty() {
+		return
+	}
+
+	clamp := func(v, min, max int) int {
+		if v < min {
+			return min
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+
+	for y := r.Min.Y - n; y < r.Max.Y+n; y++ {
+		for x := r.Min.X - n; x < r.Max.X+n; x++ {
+			if image.Pt(x, y).In(r) {
+				continue
+			}
+
+			sx := clamp(x, r.Min.X, r.Max.X-1)
+			sy := clamp(y, r.Min.Y, r.Max.Y-1)
+			img.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+}
+
+// nextPowerOfTwo returns the smallest power of two not less than v
+func nextPowerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p <<= 1
+	}
+
+	return p
+}
@@ -0,0 +1,136 @@
+package atlas
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAtlasAddFSNames(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	pix := &fstest.MapFile{Data: buf.Bytes()}
+
+	fsys := fstest.MapFS{
+		"hero.png":               pix,
+		".hidden.png":            pix,
+		"readme.txt":             {Data: []byte("not an image")},
+		"sprites/tree.png":       pix,
+		"sprites/ui/button.PNG":  pix,
+		"spritesheet/rock.png":   pix,
+		"sprites2/unrelated.png": pix,
+	}
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{".", []string{".hidden", "hero", "sprites/tree", "sprites/ui/button", "sprites2/unrelated", "spritesheet/rock"}},
+		{"sprites", []string{"tree", "ui/button"}},
+		{"sprites/ui", []string{"button"}},
+	}
+
+	for _, tt := range tests {
+		a := New(DefaultOptions())
+		if err := a.AddFS(fsys, tt.dir); err != nil {
+			t.Fatalf("AddFS(%q): %v", tt.dir, err)
+		}
+
+		got := append([]string(nil), a.names...)
+		sort.Strings(got)
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AddFS(%q) names = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestAtlasPack(t *testing.T) {
+	a := New(Options{MaxW: 64, MaxH: 64, Padding: 1, Extrude: 1, Trim: true})
+
+	colors := map[string]color.RGBA{}
+	for i, size := range []image.Point{{20, 10}, {8, 8}, {30, 30}, {5, 17}, {12, 3}, {40, 20}, {16, 16}} {
+		name := fmt.Sprintf("img%v", i)
+		c := color.RGBA{uint8(40 * i), 255, uint8(255 - 30*i), 255}
+		colors[name] = c
+
+		// a transparent border of 2 pixels to trim
+		img := image.NewRGBA(image.Rect(0, 0, size.X+4, size.Y+4))
+		draw.Draw(img, image.Rect(2, 2, size.X+2, size.Y+2), image.NewUniform(c), image.Point{}, draw.Src)
+
+		if err := a.Add(name, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := a.Add("img0", image.NewRGBA(image.Rect(0, 0, 1, 1))); err == nil {
+		t.Error("adding a duplicate name did not fail")
+	}
+
+	pages, err := a.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := 0
+	for _, page := range pages {
+		bounds := page.Image.Bounds()
+		if bounds.Dx() > 64 || bounds.Dy() > 64 {
+			t.Errorf("page of %v exceeds the maximum size", bounds.Size())
+		}
+
+		var placed []image.Rectangle
+		for name, r := range page.Sprites {
+			found++
+
+			// extrusion and padding around each sprite must not overlap others
+			outer := r.Inset(-a.Extrude)
+			if !outer.In(bounds) {
+				t.Errorf("%v at %v with extrusion is outside the page %v", name, r, bounds)
+			}
+			for _, o := range placed {
+				if outer.Inset(-a.Padding).Overlaps(o) {
+					t.Errorf("%v at %v overlaps %v", name, r, o)
+				}
+			}
+			placed = append(placed, outer)
+
+			for y := outer.Min.Y; y < outer.Max.Y; y++ {
+				for x := outer.Min.X; x < outer.Max.X; x++ {
+					if got := page.Image.RGBAAt(x, y); got != colors[name] {
+						t.Fatalf("%v pixel (%v, %v) = %v, want %v", name, x, y, got, colors[name])
+					}
+				}
+			}
+
+			trim, ok := page.Trims[name]
+			if !ok || trim.Offset != image.Pt(2, 2) || trim.Size != r.Size().Add(image.Pt(4, 4)) {
+				t.Errorf("%v trim = %+v, want offset (2, 2) and size %v", name, trim, r.Size().Add(image.Pt(4, 4)))
+			}
+		}
+	}
+
+	if found != a.Len() {
+		t.Errorf("packed %v sprites, want %v", found, a.Len())
+	}
+}
+
+func TestAtlasPackTooLarge(t *testing.T) {
+	a := New(Options{MaxW: 16, MaxH: 16})
+	if err := a.Add("big", image.NewRGBA(image.Rect(0, 0, 17, 4))); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Pack(); err == nil {
+		t.Error("packing an image larger than a page did not fail")
+	}
+}
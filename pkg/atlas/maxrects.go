@@ -0,0 +1,89 @@
+package atlas
+
+import "image"
+
+// maxRects is a MaxRects bin packer using the best short side fit heuristic
+type maxRects struct {
+	free []image.Rectangle
+}
+
+func newMaxRects(w, h int) *maxRects {
+	return &maxRects{
+		free: []image.Rectangle{image.Rect(0, 0, w, h)},
+	}
+}
+
+// insert places a w by h rectangle and returns its position. It reports false
+// if the rectangle does not fit.
+func (m *maxRects) insert(w, h int) (image.Point, bool) {
+	best := -1
+	bestShort, bestLong := 0, 0
+
+	for i, f := range m.free {
+		if f.Dx() < w || f.Dy() < h {
+			continue
+		}
+
+		dx, dy := f.Dx()-w, f.Dy()-h
+		short, long := dx, dy
+		if short > long {
+			short, long = long, short
+		}
+
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best = i
+			bestShort, bestLong = short, long
+		}
+	}
+
+	if best < 0 {
+		return image.Point{}, false
+	}
+
+	placed := image.Rect(0, 0, w, h).Add(m.free[best].Min)
+	m.split(placed)
+
+	return placed.Min, true
+}
+
+// split removes the placed rectangle from all free rectangles and prunes free
+// rectangles contained in others
+func (m *maxRects) split(used image.Rectangle) {
+	var free []image.Rectangle
+	for _, f := range m.free {
+		if !f.Overlaps(used) {
+			free = append(free, f)
+			continue
+		}
+
+		// keep the maximal free areas on each side of the used rectangle
+		if used.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, used.Min.X, f.Max.Y))
+		}
+		if used.Max.X < f.Max.X {
+			free = append(free, image.Rect(used.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+		if used.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, used.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+
+	// prune rectangles contained in others
+	m.free = m.free[:0]
+	for i, f := range free {
+		contained := false
+		for j, g := range free {
+			if i != j && f.In(g) && (f != g || j < i) {
+				contained = true
+				break
+			}
+		}
+
+		if !contained {
+			m.free = append(m.free, f)
+		}
+	}
+}
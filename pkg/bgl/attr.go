@@ -50,11 +50,29 @@ func (a AttrFormat) Offsets() (offsets []int) {
 	return
 }
 
+// Locate returns a copy of the format with the locations of each attribute set
+// from the vertex attributes of the given program. Attributes that are not
+// active in the program are given a location of -1.
+func (a AttrFormat) Locate(p *Program) AttrFormat {
+	located := make(AttrFormat, len(a))
+	for i, attr := range a {
+		attr.Loc = -1
+		if found, ok := p.VertexAttrs.Find(attr.Name); ok {
+			attr.Loc = found.Loc
+		}
+
+		located[i] = attr
+	}
+
+	return located
+}
+
 type Attr struct {
 	Type       AttrType
 	Name       string
 	Loc        int32
 	Normalized bool
+	Count      int // number of array elements, 1 if not an array (active attributes only)
 }
 
 // Size gets the size in floats of the attribute
@@ -108,18 +126,132 @@ const (
 	Mat3x4d = AttrType(gl.DOUBLE_MAT3x4)
 	Mat4x2d = AttrType(gl.DOUBLE_MAT4x2)
 	Mat4x3d = AttrType(gl.DOUBLE_MAT4x3)
+	Bool    = AttrType(gl.BOOL)
+	Vec2b   = AttrType(gl.BOOL_VEC2)
+	Vec3b   = AttrType(gl.BOOL_VEC3)
+	Vec4b   = AttrType(gl.BOOL_VEC4)
+
+	// opaque types, set to a texture or image unit
+	Sampler1D        = AttrType(gl.SAMPLER_1D)
+	Sampler2D        = AttrType(gl.SAMPLER_2D)
+	Sampler3D        = AttrType(gl.SAMPLER_3D)
+	SamplerCube      = AttrType(gl.SAMPLER_CUBE)
+	Sampler2DShadow  = AttrType(gl.SAMPLER_2D_SHADOW)
+	Sampler2DArray   = AttrType(gl.SAMPLER_2D_ARRAY)
+	Sampler2DMS      = AttrType(gl.SAMPLER_2D_MULTISAMPLE)
+	SamplerBuffer    = AttrType(gl.SAMPLER_BUFFER)
+	ISampler2D       = AttrType(gl.INT_SAMPLER_2D)
+	USampler2D       = AttrType(gl.UNSIGNED_INT_SAMPLER_2D)
+	Image2D          = AttrType(gl.IMAGE_2D)
+	Image3D          = AttrType(gl.IMAGE_3D)
+	Image2DArray     = AttrType(gl.IMAGE_2D_ARRAY)
+	IImage2D         = AttrType(gl.INT_IMAGE_2D)
+	UImage2D         = AttrType(gl.UNSIGNED_INT_IMAGE_2D)
+	ImageBuffer      = AttrType(gl.IMAGE_BUFFER)
+	IImage3D         = AttrType(gl.INT_IMAGE_3D)
+	UImage3D         = AttrType(gl.UNSIGNED_INT_IMAGE_3D)
+	Sampler1DArray   = AttrType(gl.SAMPLER_1D_ARRAY)
+	SamplerCubeArray = AttrType(gl.SAMPLER_CUBE_MAP_ARRAY)
 )
 
+var attrTypeNames = map[AttrType]string{
+	Float:   "float",
+	Vec2f:   "vec2",
+	Vec3f:   "vec3",
+	Vec4f:   "vec4",
+	Mat2f:   "mat2",
+	Mat3f:   "mat3",
+	Mat4f:   "mat4",
+	Mat2x3f: "mat2x3",
+	Mat2x4f: "mat2x4",
+	Mat3x2f: "mat3x2",
+	Mat3x4f: "mat3x4",
+	Mat4x2f: "mat4x2",
+	Mat4x3f: "mat4x3",
+	Int:     "int",
+	Vec2i:   "ivec2",
+	Vec3i:   "ivec3",
+	Vec4i:   "ivec4",
+	UInt:    "uint",
+	Vec2ui:  "uvec2",
+	Vec3ui:  "uvec3",
+	Vec4ui:  "uvec4",
+	Double:  "double",
+	Vec2d:   "dvec2",
+	Vec3d:   "dvec3",
+	Vec4d:   "dvec4",
+	Mat2d:   "dmat2",
+	Mat3d:   "dmat3",
+	Mat4d:   "dmat4",
+	Mat2x3d: "dmat2x3",
+	Mat2x4d: "dmat2x4",
+	Mat3x2d: "dmat3x2",
+	Mat3x4d: "dmat3x4",
+	Mat4x2d: "dmat4x2",
+	Mat4x3d: "dmat4x3",
+	Bool:    "bool",
+	Vec2b:   "bvec2",
+	Vec3b:   "bvec3",
+	Vec4b:   "bvec4",
+
+	Sampler1D:        "sampler1D",
+	Sampler2D:        "sampler2D",
+	Sampler3D:        "sampler3D",
+	SamplerCube:      "samplerCube",
+	Sampler2DShadow:  "sampler2DShadow",
+	Sampler2DArray:   "sampler2DArray",
+	Sampler2DMS:      "sampler2DMS",
+	SamplerBuffer:    "samplerBuffer",
+	ISampler2D:       "isampler2D",
+	USampler2D:       "usampler2D",
+	Image2D:          "image2D",
+	Image3D:          "image3D",
+	Image2DArray:     "image2DArray",
+	IImage2D:         "iimage2D",
+	UImage2D:         "uimage2D",
+	ImageBuffer:      "imageBuffer",
+	IImage3D:         "iimage3D",
+	UImage3D:         "uimage3D",
+	Sampler1DArray:   "sampler1DArray",
+	SamplerCubeArray: "samplerCubeArray",
+}
+
+// String returns the GLSL name of an attribute type
+func (a AttrType) String() string {
+	if name, ok := attrTypeNames[a]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("AttrType(0x%x)", uint32(a))
+}
+
+// Opaque reports whether the type is a sampler or image type. Opaque uniforms
+// are set to the texture or image unit they read from.
+func (a AttrType) Opaque() bool {
+	switch a {
+	case Sampler1D, Sampler2D, Sampler3D, SamplerCube, Sampler2DShadow, Sampler2DArray, Sampler2DMS, SamplerBuffer,
+		ISampler2D, USampler2D, Sampler1DArray, SamplerCubeArray,
+		Image2D, Image3D, Image2DArray, IImage2D, UImage2D, ImageBuffer, IImage3D, UImage3D:
+		return true
+	}
+
+	return false
+}
+
 // Size gets the size in elements of an attribute type
 func (a AttrType) Size() int {
+	if a.Opaque() {
+		return 1
+	}
+
 	switch a {
-	case Float:
+	case Float, Int, UInt, Double, Bool:
 		return 1
-	case Vec2f, Vec2i, Vec2ui, Vec2d:
+	case Vec2f, Vec2i, Vec2ui, Vec2d, Vec2b:
 		return 2
-	case Vec3f, Vec3i, Vec3ui, Vec3d:
+	case Vec3f, Vec3i, Vec3ui, Vec3d, Vec3b:
 		return 3
-	case Vec4f, Vec4i, Vec4ui, Vec4d:
+	case Vec4f, Vec4i, Vec4ui, Vec4d, Vec4b:
 		return 4
 	case Mat2f, Mat2d:
 		return 4
@@ -144,8 +276,45 @@ func (a AttrType) Size() int {
 	}
 }
 
+// Cols gets the number of columns of an attribute type. Matrix attributes
+// occupy one vertex attribute location per column.
+func (a AttrType) Cols() int {
+	switch a {
+	case Mat2f, Mat2d, Mat2x3f, Mat2x3d, Mat2x4f, Mat2x4d:
+		return 2
+	case Mat3f, Mat3d, Mat3x2f, Mat3x2d, Mat3x4f, Mat3x4d:
+		return 3
+	case Mat4f, Mat4d, Mat4x2f, Mat4x2d, Mat4x3f, Mat4x3d:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// Base gets the OpenGL type of a single element of an attribute type
+func (a AttrType) Base() uint32 {
+	if a.Opaque() {
+		return gl.INT
+	}
+
+	switch a {
+	case Int, Vec2i, Vec3i, Vec4i, Bool, Vec2b, Vec3b, Vec4b:
+		return gl.INT
+	case UInt, Vec2ui, Vec3ui, Vec4ui:
+		return gl.UNSIGNED_INT
+	case Double, Vec2d, Vec3d, Vec4d, Mat2d, Mat3d, Mat4d, Mat2x3d, Mat2x4d, Mat3x2d, Mat3x4d, Mat4x2d, Mat4x3d:
+		return gl.DOUBLE
+	default:
+		return gl.FLOAT
+	}
+}
+
 // Len gets the size in bytes of an attribute type.
 func (a AttrType) Len() int {
+	if a.Opaque() {
+		return 4
+	}
+
 	switch a {
 	case Float:
 		return 4
@@ -215,6 +384,14 @@ func (a AttrType) Len() int {
 		return 64
 	case Mat4x3d:
 		return 96
+	case Bool:
+		return 4
+	case Vec2b:
+		return 8
+	case Vec3b:
+		return 12
+	case Vec4b:
+		return 16
 	default:
 		return 0
 	}
@@ -52,6 +52,44 @@ func SetBounds(x, y, w, h int) {
 	gl.Scissor(int32(x), int32(y), int32(w), int32(h))
 }
 
+func EnableScissor() {
+	gl.Enable(gl.SCISSOR_TEST)
+}
+
+func DisableScissor() {
+	gl.Disable(gl.SCISSOR_TEST)
+}
+
+// ScissorTest reports whether the scissor test is enabled
+func ScissorTest() bool {
+	return gl.IsEnabled(gl.SCISSOR_TEST)
+}
+
+// Scissor returns the scissor box
+func Scissor() [4]float32 {
+	var box [4]float32
+	gl.GetFloatv(gl.SCISSOR_BOX, &box[0])
+	return box
+}
+
+// SetScissor sets the scissor box without changing the viewport
+func SetScissor(x, y, w, h int) {
+	gl.Scissor(int32(x), int32(y), int32(w), int32(h))
+}
+
+func EnableDepthTest() {
+	gl.Enable(gl.DEPTH_TEST)
+}
+
+func DisableDepthTest() {
+	gl.Disable(gl.DEPTH_TEST)
+}
+
+// DepthTest reports whether depth testing is enabled
+func DepthTest() bool {
+	return gl.IsEnabled(gl.DEPTH_TEST)
+}
+
 // Viewport returns the viewport
 func Viewport() [4]float32 {
 	var vp [4]float32
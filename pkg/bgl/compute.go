@@ -0,0 +1,86 @@
+package bgl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+// Access is the access of a shader to an image
+type Access uint32
+
+const (
+	ReadOnly  = Access(gl.READ_ONLY)
+	WriteOnly = Access(gl.WRITE_ONLY)
+	ReadWrite = Access(gl.READ_WRITE)
+)
+
+// Barrier is a set of memory barrier bits, see MemoryBarrier
+type Barrier uint32
+
+const (
+	VertexAttribBarrier  = Barrier(gl.VERTEX_ATTRIB_ARRAY_BARRIER_BIT) // vertex data written by shaders
+	ElementArrayBarrier  = Barrier(gl.ELEMENT_ARRAY_BARRIER_BIT)       // indices written by shaders
+	UniformBarrier       = Barrier(gl.UNIFORM_BARRIER_BIT)             // uniform buffers written by shaders
+	TextureFetchBarrier  = Barrier(gl.TEXTURE_FETCH_BARRIER_BIT)       // textures sampled after image stores
+	ImageAccessBarrier   = Barrier(gl.SHADER_IMAGE_ACCESS_BARRIER_BIT) // image loads after image stores
+	CommandBarrier       = Barrier(gl.COMMAND_BARRIER_BIT)             // indirect commands written by shaders
+	BufferUpdateBarrier  = Barrier(gl.BUFFER_UPDATE_BARRIER_BIT)       // buffer reads (e.g. GetData) after shader writes
+	TextureUpdateBarrier = Barrier(gl.TEXTURE_UPDATE_BARRIER_BIT)      // texture reads (e.g. Pixels) after shader writes
+	FramebufferBarrier   = Barrier(gl.FRAMEBUFFER_BARRIER_BIT)         // framebuffer access after shader writes
+	StorageBarrier       = Barrier(gl.SHADER_STORAGE_BARRIER_BIT)      // storage buffer access after shader writes
+	AllBarriers          = Barrier(gl.ALL_BARRIER_BITS)
+)
+
+// MemoryBarrier orders memory accesses of shaders before the barrier (e.g. a
+// compute dispatch) with the accesses described by b after it
+func MemoryBarrier(b Barrier) {
+	gl.MemoryBarrier(uint32(b))
+}
+
+// WorkGroupSize returns the local work group size declared by a compute
+// program ("layout(local_size_x = ...) in")
+func (p *Program) WorkGroupSize() [3]int {
+	var size [3]int32
+	gl.GetProgramiv(p.ID, gl.COMPUTE_WORK_GROUP_SIZE, &size[0])
+
+	return [3]int{int(size[0]), int(size[1]), int(size[2])}
+}
+
+// Dispatch runs a compute program with the given number of work groups in each
+// dimension. Results are only visible to later commands after a matching
+// MemoryBarrier.
+func (p *Program) Dispatch(x, y, z int) {
+	p.Bind()
+	gl.DispatchCompute(uint32(x), uint32(y), uint32(z))
+	p.Unbind()
+}
+
+// DispatchSize runs a compute program over at least x by y by z invocations,
+// rounding the number of work groups up to cover them (see WorkGroupSize).
+// It fails for programs without a work group size, i.e. non-compute programs.
+func (p *Program) DispatchSize(x, y, z int) error {
+	size := p.WorkGroupSize()
+	if size[0] <= 0 || size[1] <= 0 || size[2] <= 0 {
+		return fmt.Errorf("program %v has no work group size %v, is it a compute program?", p.ID, size)
+	}
+
+	p.Dispatch(
+		(x+size[0]-1)/size[0],
+		(y+size[1]-1)/size[1],
+		(z+size[2]-1)/size[2],
+	)
+
+	return nil
+}
+
+// BindImage binds level 0 of the Texture to an image unit for image load and
+// store ("layout(rgba8, binding = unit) uniform image2D")
+func (t *Texture) BindImage(unit uint32, access Access) {
+	gl.BindImageTexture(unit, t.ID, 0, false, 0, uint32(access), t.format.Internal)
+}
+
+// BindUnit binds the Texture to a texture unit for sampling
+func (t *Texture) BindUnit(unit uint32) {
+	gl.BindTextureUnit(unit, t.ID)
+}
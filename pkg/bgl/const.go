@@ -7,6 +7,7 @@ type Usage uint32
 const (
 	DynamicDraw = Usage(gl.DYNAMIC_DRAW)
 	StaticDraw  = Usage(gl.STATIC_DRAW)
+	StreamDraw  = Usage(gl.STREAM_DRAW)
 )
 
 type DrawMode uint32
@@ -55,6 +55,16 @@ func (ebo *EBO) Draw() {
 	gl.DrawElements(uint32(ebo.DrawMode), int32(ebo.size), gl.UNSIGNED_INT, nil)
 }
 
+// DrawInstanced draws the EBO once for each instance
+func (ebo *EBO) DrawInstanced(instances int) {
+	if ebo.size == 0 || instances == 0 {
+		return
+	}
+
+	ebo.Bind()
+	gl.DrawElementsInstanced(uint32(ebo.DrawMode), int32(ebo.size), gl.UNSIGNED_INT, nil, int32(instances))
+}
+
 // Delete
 func (ebo *EBO) Delete() {
 	gl.DeleteBuffers(1, &ebo.ID)
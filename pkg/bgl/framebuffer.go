@@ -0,0 +1,333 @@
+package bgl
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+// Attachment is a framebuffer attachment point
+type Attachment uint32
+
+const (
+	DepthAttachment        = Attachment(gl.DEPTH_ATTACHMENT)
+	StencilAttachment      = Attachment(gl.STENCIL_ATTACHMENT)
+	DepthStencilAttachment = Attachment(gl.DEPTH_STENCIL_ATTACHMENT)
+)
+
+// ColorAttachment returns the i-th color attachment point
+func ColorAttachment(i int) Attachment {
+	return Attachment(gl.COLOR_ATTACHMENT0 + uint32(i))
+}
+
+// color reports whether the attachment is a color attachment
+func (a Attachment) color() bool {
+	return a >= gl.COLOR_ATTACHMENT0 && a < gl.COLOR_ATTACHMENT0+32
+}
+
+// BufferBit selects the buffers to clear or blit
+type BufferBit uint32
+
+const (
+	ColorBit   = BufferBit(gl.COLOR_BUFFER_BIT)
+	DepthBit   = BufferBit(gl.DEPTH_BUFFER_BIT)
+	StencilBit = BufferBit(gl.STENCIL_BUFFER_BIT)
+)
+
+// Renderbuffer is an OpenGL renderbuffer, a framebuffer attachment that can
+// not be sampled (e.g. a depth buffer or a multisampled color buffer)
+type Renderbuffer struct {
+	ID uint32
+
+	width, height int
+	format        TextureFormat
+	samples       int
+}
+
+// NewRenderbuffer creates a renderbuffer of the given size and format.
+// Samples greater than zero create a multisampled renderbuffer, which can be
+// resolved by blitting its framebuffer.
+func NewRenderbuffer(width, height int, format TextureFormat, samples int) *Renderbuffer {
+	rb := &Renderbuffer{
+		format:  format,
+		samples: samples,
+	}
+
+	gl.CreateRenderbuffers(1, &rb.ID)
+
+	rb.Resize(width, height)
+
+	runtime.SetFinalizer(rb, (*Renderbuffer).Delete)
+
+	return rb
+}
+
+// Resize reallocates the renderbuffer with the given size
+func (rb *Renderbuffer) Resize(width, height int) {
+	rb.width = width
+	rb.height = height
+
+	gl.NamedRenderbufferStorageMultisample(rb.ID, int32(rb.samples), rb.format.Internal, int32(width), int32(height))
+}
+
+// Width returns the width of the renderbuffer in pixels
+func (rb *Renderbuffer) Width() int {
+	return rb.width
+}
+
+// Height returns the height of the renderbuffer in pixels
+func (rb *Renderbuffer) Height() int {
+	return rb.height
+}
+
+// Delete deletes the renderbuffer
+func (rb *Renderbuffer) Delete() {
+	gl.DeleteRenderbuffers(1, &rb.ID)
+	rb.ID = 0
+
+	runtime.SetFinalizer(rb, nil)
+}
+
+// attachment is a texture or renderbuffer attached to a framebuffer
+type attachment struct {
+	point Attachment
+	tex   *Texture
+	rb    *Renderbuffer
+}
+
+// Framebuffer is an OpenGL framebuffer object rendering into textures and
+// renderbuffers. While bound, drawing goes to its attachments and the viewport
+// covers them; Unbind restores the previous framebuffer and viewport.
+type Framebuffer struct {
+	ID uint32
+
+	width, height int
+	attachments   []attachment
+
+	// state restored by Unbind
+	prevFB       int32
+	prevViewport [4]int32
+	prevScissor  [4]int32
+}
+
+// NewFramebuffer creates an empty framebuffer of the given size. Attach
+// textures or renderbuffers of (at least) that size before drawing to it.
+func NewFramebuffer(width, height int) *Framebuffer {
+	fb := &Framebuffer{
+		width:  width,
+		height: height,
+	}
+
+	gl.CreateFramebuffers(1, &fb.ID)
+
+	runtime.SetFinalizer(fb, (*Framebuffer).Delete)
+
+	return fb
+}
+
+// attach replaces or adds an attachment and updates the draw buffers
+func (fb *Framebuffer) attach(a attachment) {
+	replaced := false
+	for i, old := range fb.attachments {
+		if old.point == a.point {
+			fb.attachments[i] = a
+			replaced = true
+		}
+	}
+	if !replaced {
+		fb.attachments = append(fb.attachments, a)
+	}
+
+	var bufs []uint32
+	for _, a := range fb.attachments {
+		if a.point.color() {
+			bufs = append(bufs, uint32(a.point))
+		}
+	}
+
+	if len(bufs) == 0 {
+		gl.NamedFramebufferDrawBuffer(fb.ID, gl.NONE)
+		gl.NamedFramebufferReadBuffer(fb.ID, gl.NONE)
+		return
+	}
+
+	// multiple render targets, "layout(location = i) out" writes to the i-th
+	// color attachment in attachment order
+	gl.NamedFramebufferDrawBuffers(fb.ID, int32(len(bufs)), &bufs[0])
+	gl.NamedFramebufferReadBuffer(fb.ID, bufs[0])
+}
+
+// AttachTexture attaches level 0 of a texture
+func (fb *Framebuffer) AttachTexture(point Attachment, t *Texture) {
+	gl.NamedFramebufferTexture(fb.ID, uint32(point), t.ID, 0)
+
+	fb.attach(attachment{point: point, tex: t})
+}
+
+// AttachRenderbuffer attaches a renderbuffer
+func (fb *Framebuffer) AttachRenderbuffer(point Attachment, rb *Renderbuffer) {
+	gl.NamedFramebufferRenderbuffer(fb.ID, uint32(point), gl.RENDERBUFFER, rb.ID)
+
+	fb.attach(attachment{point: point, rb: rb})
+}
+
+// Texture returns the texture attached at the given point, nil if none
+func (fb *Framebuffer) Texture(point Attachment) *Texture {
+	for _, a := range fb.attachments {
+		if a.point == point {
+			return a.tex
+		}
+	}
+
+	return nil
+}
+
+// Check returns a descriptive error if the framebuffer is not complete
+func (fb *Framebuffer) Check() error {
+	status := gl.CheckNamedFramebufferStatus(fb.ID, gl.FRAMEBUFFER)
+
+	switch status {
+	case gl.FRAMEBUFFER_COMPLETE:
+		return nil
+	case gl.FRAMEBUFFER_UNDEFINED:
+		return fmt.Errorf("framebuffer incomplete: default framebuffer does not exist")
+	case gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:
+		return fmt.Errorf("framebuffer incomplete: an attachment is incomplete or has a format that cannot be rendered to")
+	case gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:
+		return fmt.Errorf("framebuffer incomplete: no attachments")
+	case gl.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:
+		return fmt.Errorf("framebuffer incomplete: a draw buffer has no attachment")
+	case gl.FRAMEBUFFER_INCOMPLETE_READ_BUFFER:
+		return fmt.Errorf("framebuffer incomplete: the read buffer has no attachment")
+	case gl.FRAMEBUFFER_UNSUPPORTED:
+		return fmt.Errorf("framebuffer incomplete: the combination of attachment formats is not supported")
+	case gl.FRAMEBUFFER_INCOMPLETE_MULTISAMPLE:
+		return fmt.Errorf("framebuffer incomplete: attachments have different numbers of samples")
+	case gl.FRAMEBUFFER_INCOMPLETE_LAYER_TARGETS:
+		return fmt.Errorf("framebuffer incomplete: attachments are not all layered")
+	case 0:
+		return fmt.Errorf("framebuffer incomplete: status check failed")
+	}
+
+	return fmt.Errorf("framebuffer incomplete: unknown status 0x%x", status)
+}
+
+// Width returns the width of the framebuffer in pixels
+func (fb *Framebuffer) Width() int {
+	return fb.width
+}
+
+// Height returns the height of the framebuffer in pixels
+func (fb *Framebuffer) Height() int {
+	return fb.height
+}
+
+// Resize resizes the framebuffer and reallocates all of its attachments,
+// discarding their content
+func (fb *Framebuffer) Resize(width, height int) {
+	fb.width = width
+	fb.height = height
+
+	for _, a := range fb.attachments {
+		if a.tex != nil {
+			a.tex.Resize(width, height)
+			gl.NamedFramebufferTexture(fb.ID, uint32(a.point), a.tex.ID, 0)
+		} else {
+			a.rb.Resize(width, height)
+			gl.NamedFramebufferRenderbuffer(fb.ID, uint32(a.point), gl.RENDERBUFFER, a.rb.ID)
+		}
+	}
+}
+
+// Bind binds the framebuffer for drawing and sets the viewport to cover it,
+// remembering the previous framebuffer and viewport for Unbind
+func (fb *Framebuffer) Bind() {
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &fb.prevFB)
+	gl.GetIntegerv(gl.VIEWPORT, &fb.prevViewport[0])
+	gl.GetIntegerv(gl.SCISSOR_BOX, &fb.prevScissor[0])
+
+	// only the draw binding is saved, so only the draw binding is changed
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, fb.ID)
+	SetBounds(0, 0, fb.width, fb.height)
+}
+
+// Unbind restores the draw framebuffer and viewport active before Bind
+func (fb *Framebuffer) Unbind() {
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(fb.prevFB))
+
+	v, s := fb.prevViewport, fb.prevScissor
+	gl.Viewport(v[0], v[1], v[2], v[3])
+	gl.Scissor(s[0], s[1], s[2], s[3])
+}
+
+// Blit copies the selected buffers of the whole framebuffer into dst, scaling
+// them to its size. A nil dst is the default framebuffer, covering the current
+// viewport. Blitting a multisampled framebuffer into a single sampled one
+// resolves it; filter must be Nearest when blitting depth or stencil.
+func (fb *Framebuffer) Blit(dst *Framebuffer, mask BufferBit, filter Filter) {
+	var id uint32
+	var x, y, w, h int32
+	if dst != nil {
+		id = dst.ID
+		w, h = int32(dst.width), int32(dst.height)
+	} else {
+		var vp [4]int32
+		gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+		x, y, w, h = vp[0], vp[1], vp[2], vp[3]
+	}
+
+	gl.BlitNamedFramebuffer(
+		fb.ID, id,
+		0, 0, int32(fb.width), int32(fb.height),
+		x, y, x+w, y+h,
+		uint32(mask), uint32(filter),
+	)
+}
+
+// ReadImage reads a color attachment into an image, e.g. for screenshots of
+// offscreen renders. The read framebuffer and read buffer are restored.
+func (fb *Framebuffer) ReadImage(i int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, fb.width, fb.height))
+
+	var prev, prevBuf int32
+	gl.GetIntegerv(gl.READ_FRAMEBUFFER_BINDING, &prev)
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, fb.ID)
+	gl.GetIntegerv(gl.READ_BUFFER, &prevBuf)
+
+	gl.NamedFramebufferReadBuffer(fb.ID, uint32(ColorAttachment(i)))
+	gl.ReadPixels(0, 0, int32(fb.width), int32(fb.height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.NamedFramebufferReadBuffer(fb.ID, uint32(prevBuf))
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(prev))
+
+	// OpenGL rows start at the bottom
+	stride := img.Stride
+	row := make([]uint8, stride)
+	for y := 0; y < fb.height/2; y++ {
+		top := img.Pix[y*stride : (y+1)*stride]
+		bottom := img.Pix[(fb.height-1-y)*stride : (fb.height-y)*stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+
+	return img
+}
+
+// Clear clears the selected buffers of the framebuffer
+func (fb *Framebuffer) Clear(mask BufferBit) {
+	fb.Bind()
+	gl.Clear(uint32(mask))
+	fb.Unbind()
+}
+
+// Delete deletes the framebuffer. Attachments are not deleted.
+func (fb *Framebuffer) Delete() {
+	gl.DeleteFramebuffers(1, &fb.ID)
+	fb.ID = 0
+
+	runtime.SetFinalizer(fb, nil)
+}
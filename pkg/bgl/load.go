@@ -0,0 +1,62 @@
+package bgl
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ShaderTypeOf returns the shader type for a file name by its extension:
+// ".frag", ".vert", ".comp" or ".geom"
+func ShaderTypeOf(name string) (ShaderType, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".frag":
+		return FragShader, nil
+	case ".vert":
+		return VertShader, nil
+	case ".comp":
+		return CompShader, nil
+	case ".geom":
+		return GeomShader, nil
+	}
+
+	return 0, fmt.Errorf("unknown shader type for \"%v\"", name)
+}
+
+// LoadShaderFS reads a shader source from fsys. The shader type is derived
+// from the file extension (see ShaderTypeOf).
+func LoadShaderFS(fsys fs.FS, name string) (*Shader, error) {
+	stype, err := ShaderTypeOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shader: %w", err)
+	}
+
+	return NewShader(string(src)+"\x00", stype), nil
+}
+
+// LoadProgramFS reads, compiles and links a program from shader sources in
+// fsys, e.g. LoadProgramFS(assets, "shaders/sprite.vert", "shaders/sprite.frag")
+func LoadProgramFS(fsys fs.FS, names ...string) (*Program, error) {
+	shaders := make([]*Shader, 0, len(names))
+	for _, name := range names {
+		s, err := LoadShaderFS(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		shaders = append(shaders, s)
+	}
+
+	p, err := NewProgram(shaders)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", strings.Join(names, ", "), err)
+	}
+
+	return p, nil
+}
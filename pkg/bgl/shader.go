@@ -21,10 +21,17 @@ var (
 	//go:embed src/*
 	embedded embed.FS
 
-	DefaultFrag string // Default frag source included for convenience
-	DefaultVert string // Default vertex source included for convenience
+	DefaultFrag  string // Default frag source included for convenience
+	DefaultVert  string // Default vertex source included for convenience
+	BatchFrag    string // Batch frag source for pre-transformed, color masked vertices
+	BatchVert    string // Batch vertex source for pre-transformed, color masked vertices
+	InstanceVert string // Instance vertex source for per-instance transformed quads
 )
 
+// CameraBinding is the uniform block binding point of the "Camera" block
+// (mat4 view, mat4 proj) used by the stock vertex shaders
+const CameraBinding = 0
+
 // init loads the default shader sources
 func init() {
 	emb, _ := fs.Sub(embedded, "src")
@@ -34,6 +41,15 @@ func init() {
 
 	vert, _ := fs.ReadFile(emb, "default.vert")
 	DefaultVert = string(vert) + "\x00"
+
+	frag, _ = fs.ReadFile(emb, "batch.frag")
+	BatchFrag = string(frag) + "\x00"
+
+	vert, _ = fs.ReadFile(emb, "batch.vert")
+	BatchVert = string(vert) + "\x00"
+
+	vert, _ = fs.ReadFile(emb, "instance.vert")
+	InstanceVert = string(vert) + "\x00"
 }
 
 // DefaultProgram returns a program with the default shaders
@@ -44,6 +60,23 @@ func DefaultProgram() (*Program, error) {
 	})
 }
 
+// BatchProgram returns a program with the batch shaders
+func BatchProgram() (*Program, error) {
+	return NewProgram([]*Shader{
+		NewShader(BatchFrag, FragShader),
+		NewShader(BatchVert, VertShader),
+	})
+}
+
+// InstanceProgram returns a program with the instance vertex shader and the
+// batch fragment shader
+func InstanceProgram() (*Program, error) {
+	return NewProgram([]*Shader{
+		NewShader(BatchFrag, FragShader),
+		NewShader(InstanceVert, VertShader),
+	})
+}
+
 // Shader is an OpenGL shader
 type Shader struct {
 	stype    ShaderType
@@ -128,11 +161,21 @@ type Program struct {
 	VertexAttrs  AttrFormat // vertex attribute format
 	UniformAttrs AttrFormat // uniform attribute format
 
+	UniformBlocks []UniformBlock // active uniform blocks
+
+	uniforms map[string]Attr // uniforms by name, with and without "[0]" for arrays
+
 	compiled bool
 }
 
 // NewProgram creates a new program
 func NewProgram(shaders []*Shader) (*Program, error) {
+	return newProgram(shaders, nil)
+}
+
+// newProgram creates a new program, binding the named vertex attributes to
+// the given locations before linking
+func newProgram(shaders []*Shader, locs map[string]uint32) (*Program, error) {
 	p := &Program{
 		VertexAttrs:  AttrFormat{},
 		UniformAttrs: AttrFormat{},
@@ -144,31 +187,41 @@ func NewProgram(shaders []*Shader) (*Program, error) {
 
 	p.ID = gl.CreateProgram()
 
+	// shaders are flagged for deletion and freed along with the program
+	defer func() {
+		for _, s := range shaders {
+			s.delete()
+		}
+	}()
+
 	for _, s := range shaders {
 		if err := s.compile(); err != nil {
+			gl.DeleteProgram(p.ID)
 			return nil, fmt.Errorf("failed to compile shader program: %w", err)
 		}
 
 		p.attach(s)
 	}
 
+	for name, loc := range locs {
+		gl.BindAttribLocation(p.ID, loc, gl.Str(name+"\x00"))
+	}
+
 	p.link()
 
 	status := p.getiv(gl.LINK_STATUS)
 	if status == gl.FALSE {
 		log := p.GetInfoLog()
+		gl.DeleteProgram(p.ID)
 
 		return nil, fmt.Errorf("failed to link shader program: %v", log)
 	}
 
-	for _, s := range shaders {
-		s.delete()
-	}
-
 	p.compiled = true
 
 	p.findUniforms()
 	p.findAttributes()
+	p.findBlocks()
 
 	runtime.SetFinalizer(p, (*Program).Delete)
 
@@ -223,13 +276,20 @@ func (p *Program) findUniforms() {
 		gl.GetActiveUniform(p.ID, i, 256, &l, &s, &t, &b[0])
 
 		a := Attr{
-			Name: gl.GoStr(&b[0]),
-			Type: AttrType(t),
-			Loc:  gl.GetUniformLocation(p.ID, &b[0]),
+			Name:  gl.GoStr(&b[0]),
+			Type:  AttrType(t),
+			Loc:   gl.GetUniformLocation(p.ID, &b[0]),
+			Count: int(s),
 		}
 
 		p.UniformAttrs[i] = a
 	}
+
+	p.uniforms = make(map[string]Attr, len(p.UniformAttrs))
+	for _, a := range p.UniformAttrs {
+		p.uniforms[a.Name] = a
+		p.uniforms[strings.TrimSuffix(a.Name, "[0]")] = a
+	}
 }
 
 // findAttributes finds the attributes in the program
@@ -247,9 +307,10 @@ func (p *Program) findAttributes() {
 		gl.GetActiveAttrib(p.ID, i, 256, &l, &s, &t, &b[0])
 
 		a := Attr{
-			Name: gl.GoStr(&b[0]),
-			Type: AttrType(t),
-			Loc:  gl.GetAttribLocation(p.ID, &b[0]),
+			Name:  gl.GoStr(&b[0]),
+			Type:  AttrType(t),
+			Loc:   gl.GetAttribLocation(p.ID, &b[0]),
+			Count: int(s),
 		}
 
 		p.VertexAttrs[i] = a
@@ -266,129 +327,57 @@ func (p *Program) Unbind() {
 	gl.UseProgram(0)
 }
 
-// SetUniform sets the value of the given uniform
-func (p *Program) SetUniform(name string, value interface{}) error {
-	var attr Attr
+// deleteProgram deletes the program.
+func (p *Program) Delete() {
+	gl.DeleteProgram(p.ID)
+	p.ID = 0
 
-	attr, ok := p.VertexAttrs.Find(name)
-	if !ok {
-		attr, ok = p.UniformAttrs.Find(name)
-		if !ok {
-			return fmt.Errorf("attribute not found: %v", name)
+	runtime.SetFinalizer(p, nil)
+}
+
+// Reload recompiles the program from new shaders in place, so that everything
+// holding the program picks up the change. Vertex attributes keep their
+// locations, so that VAOs located against the old program keep working, and
+// uniform block bindings are kept. If compiling or linking fails, or an
+// attribute changes its type or (explicit) location, the old program is kept
+// and the error is returned.
+func (p *Program) Reload(shaders []*Shader) error {
+	locs := map[string]uint32{}
+	for _, a := range p.VertexAttrs {
+		if a.Loc >= 0 {
+			locs[a.Name] = uint32(a.Loc)
 		}
 	}
 
-	switch AttrType(attr.Type) {
-	case Float:
-		v := value.(float32)
-		gl.Uniform1fv(attr.Loc, 1, &v)
-	// case Vec2f:
-	// 	v := value.(mgl32.Vec2)
-	// 	gl.Uniform2fv(attr.Loc, 1, &v[0])
-	// case Vec3f:
-	// 	v := value.(mgl32.Vec3)
-	// 	gl.Uniform3fv(attr.Loc, 1, &v[0])
-	case Vec4f:
-		v := value.([4]float32)
-		gl.Uniform4fv(attr.Loc, 1, &v[0])
-	// case Mat2f:
-	// 	v := value.(mgl32.Mat2)
-	// 	gl.UniformMatrix2fv(attr.Loc, 1, false, &v[0])
-	// case Mat3f:
-	// 	v := value.(mgl32.Mat3)
-	// 	gl.UniformMatrix3fv(attr.Loc, 1, false, &v[0])
-	case Mat4f:
-		v := value.([16]float32)
-		gl.UniformMatrix4fv(attr.Loc, 1, false, &v[0])
-	// case Mat2x3f:
-	// 	v := value.(mgl32.Mat2x3)
-	// 	gl.UniformMatrix2x3fv(attr.Loc, 1, false, &v[0])
-	// case Mat2x4f:
-	// 	v := value.(mgl32.Mat2x4)
-	// 	gl.UniformMatrix2x4fv(attr.Loc, 1, false, &v[0])
-	// case Mat3x2f:
-	// 	v := value.(mgl32.Mat3x2)
-	// 	gl.UniformMatrix3x2fv(attr.Loc, 1, false, &v[0])
-	// case Mat3x4f:
-	// 	v := value.(mgl32.Mat3x4)
-	// 	gl.UniformMatrix3x4fv(attr.Loc, 1, false, &v[0])
-	// case Mat4x2f:
-	// 	v := value.(mgl32.Mat4x2)
-	// 	gl.UniformMatrix4x2fv(attr.Loc, 1, false, &v[0])
-	// case Mat4x3f:
-	// 	v := value.(mgl32.Mat4x3)
-	// 	gl.UniformMatrix4x3fv(attr.Loc, 1, false, &v[0])
-	case Int:
-		v := value.(int32)
-		gl.Uniform1iv(attr.Loc, 1, &v)
-	// case Vec2i:
-	// 	v := value.(mgl32.Vec2)
-	// 	gl.Uniform2iv(attr.Loc, 1, &v)
-	// case Vec3i:
-	// 	v := value.(int32)
-	// 	gl.Uniform1iv(attr.Loc, 1, &v)
-	// case Vec4i:
-	// 	v := value.(int32)
-	// 	gl.Uniform1iv(attr.Loc, 1, &v)
-	case UInt:
-		v := value.(uint32)
-		gl.Uniform1uiv(attr.Loc, 1, &v)
-		// case Vec2ui:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Vec3ui:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Vec4ui:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Double:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Vec2d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Vec3d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Vec4d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat2d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat3d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat4d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat2x3d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat2x4d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat3x2d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat3x4d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat4x2d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-		// case Mat4x3d:
-		// 	v := value.(int32)
-		// 	gl.Uniform1iv(attr.Loc, 1, &v)
-	default:
-		return fmt.Errorf("invalid attribute type: %v", attr.Type)
+	np, err := newProgram(shaders, locs)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	runtime.SetFinalizer(np, nil)
+
+	for _, a := range p.VertexAttrs {
+		na, ok := np.VertexAttrs.Find(a.Name)
+		if ok && (na.Loc != a.Loc || na.Type != a.Type) {
+			gl.DeleteProgram(np.ID)
+
+			return fmt.Errorf("vertex attribute \"%v\" changed from %v at location %v to %v at location %v", a.Name, a.Type, a.Loc, na.Type, na.Loc)
+		}
+	}
+
+	for _, b := range p.UniformBlocks {
+		// blocks missing from the new program are fine
+		_ = np.SetBlockBinding(b.Name, b.Binding)
+	}
 
-// deleteProgram deletes the program.
-func (p *Program) Delete() {
 	gl.DeleteProgram(p.ID)
+
+	p.ID = np.ID
+	p.VertexAttrs = np.VertexAttrs
+	p.UniformAttrs = np.UniformAttrs
+	p.uniforms = np.uniforms
+	p.UniformBlocks = np.UniformBlocks
+	p.compiled = true
+
+	return nil
 }
@@ -0,0 +1,12 @@
+#version 460 core
+
+in vec2 uv;
+in vec4 mask;
+
+out vec4 out_color;
+
+uniform sampler2D img;
+
+void main() {
+	out_color = texture(img, uv) * mask;
+}
@@ -0,0 +1,20 @@
+#version 460 core
+
+in vec3 pos; // world space vertex position {x, y, z}
+in vec2 tex; // texture coordinates {u, v}
+in vec4 col; // color mask {r, g, b, a}
+
+out vec2 uv;
+out vec4 mask;
+
+layout(std140, binding = 0) uniform Camera {
+	mat4 view;
+	mat4 proj;
+};
+
+void main() {
+	uv = tex;
+	mask = col;
+
+	gl_Position = proj * view * vec4(pos, 1.0);
+}
@@ -5,8 +5,10 @@ in vec4 tex; // vertex and texture coordinates {x, y, u, v}
 out vec2 uv;
 
 uniform mat4 modl;
-uniform mat4 view;
-uniform mat4 proj;
+layout(std140, binding = 0) uniform Camera {
+	mat4 view;
+	mat4 proj;
+};
 
 void main() {
 	uv = tex.zw;
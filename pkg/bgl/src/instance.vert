@@ -0,0 +1,21 @@
+#version 460 core
+
+in vec4 tex;  // unit quad vertex and texture coordinates {x, y, u, v}
+in mat4 modl; // per instance model matrix
+in vec4 rect; // per instance texture rectangle {u, v, w, h}
+in vec4 tint; // per instance color mask {r, g, b, a}
+
+out vec2 uv;
+out vec4 mask;
+
+layout(std140, binding = 0) uniform Camera {
+	mat4 view;
+	mat4 proj;
+};
+
+void main() {
+	uv = rect.xy + tex.zw * rect.zw;
+	mask = tint;
+
+	gl_Position = proj * view * modl * vec4(tex.xy, 0.0, 1.0);
+}
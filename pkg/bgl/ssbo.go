@@ -0,0 +1,184 @@
+package bgl
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+// StorageBuffer is a shader storage buffer object bound to a storage block
+// binding point ("layout(std430, binding = n) buffer").
+//
+// Data is uploaded from and read back into slices of fixed size Go values
+// (numbers, arrays and structs of them). The Go memory layout of the values
+// must match the std430 layout of the block; note that vec3 is aligned like
+// vec4, so structs usually need explicit padding.
+type StorageBuffer struct {
+	Usage
+	ID      uint32
+	Binding uint32
+
+	size int
+}
+
+// NewStorageBuffer creates a storage buffer for the given binding point
+func NewStorageBuffer(binding uint32) *StorageBuffer {
+	sb := &StorageBuffer{
+		Usage:   DynamicDraw,
+		Binding: binding,
+	}
+
+	gl.GenBuffers(1, &sb.ID)
+
+	runtime.SetFinalizer(sb, (*StorageBuffer).Delete)
+
+	return sb
+}
+
+// Bind binds the buffer to its binding point
+func (sb *StorageBuffer) Bind() {
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, sb.Binding, sb.ID)
+}
+
+// SetBinding moves the buffer to another binding point
+func (sb *StorageBuffer) SetBinding(binding uint32) {
+	sb.Binding = binding
+
+	if sb.size > 0 {
+		sb.Bind()
+	}
+}
+
+// Len returns the size of the buffer in bytes
+func (sb *StorageBuffer) Len() int {
+	return sb.size
+}
+
+// Alloc resizes the buffer to the given number of bytes with undefined
+// content, e.g. for buffers only written by shaders
+func (sb *StorageBuffer) Alloc(size int) {
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, sb.ID)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, size, nil, uint32(sb.Usage))
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+
+	sb.size = size
+	sb.Bind()
+}
+
+// SetData replaces the content of the buffer with a slice of values, resizing
+// the buffer to fit
+func (sb *StorageBuffer) SetData(data interface{}) error {
+	size, err := storageSize(data)
+	if err != nil {
+		return err
+	}
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, sb.ID)
+	if size == sb.size {
+		gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, size, gl.Ptr(data))
+	} else {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, size, gl.Ptr(data), uint32(sb.Usage))
+		sb.size = size
+	}
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+
+	sb.Bind()
+
+	return nil
+}
+
+// SetSubData writes a slice of values into the buffer at the given offset in
+// bytes
+func (sb *StorageBuffer) SetSubData(offset int, data interface{}) error {
+	size, err := storageSize(data)
+	if err != nil {
+		return err
+	}
+
+	if offset < 0 || offset+size > sb.size {
+		return fmt.Errorf("write of %v bytes at %v exceeds the %v byte buffer", size, offset, sb.size)
+	}
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, sb.ID)
+	gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, offset, size, gl.Ptr(data))
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+
+	return nil
+}
+
+// GetData reads the buffer into a slice of values, filling as many elements as
+// the slice holds. Issue MemoryBarrier(BufferUpdateBarrier) after a dispatch
+// writing the buffer before reading it.
+func (sb *StorageBuffer) GetData(dst interface{}) error {
+	return sb.GetSubData(0, dst)
+}
+
+// GetSubData reads the buffer from the given offset in bytes into a slice of
+// values
+func (sb *StorageBuffer) GetSubData(offset int, dst interface{}) error {
+	size, err := storageSize(dst)
+	if err != nil {
+		return err
+	}
+
+	if offset < 0 || offset+size > sb.size {
+		return fmt.Errorf("read of %v bytes at %v exceeds the %v byte buffer", size, offset, sb.size)
+	}
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, sb.ID)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, offset, size, gl.Ptr(dst))
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+
+	return nil
+}
+
+// Delete deletes the buffer
+func (sb *StorageBuffer) Delete() {
+	gl.DeleteBuffers(1, &sb.ID)
+	sb.ID = 0
+
+	runtime.SetFinalizer(sb, nil)
+}
+
+// storageSize returns the size in bytes of a non-empty slice of fixed size
+// values
+func storageSize(data interface{}) (int, error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("expected a slice, got %T", data)
+	}
+
+	if v.Len() == 0 {
+		return 0, fmt.Errorf("empty slice")
+	}
+
+	if !plainType(v.Type().Elem()) {
+		return 0, fmt.Errorf("%v contains references and cannot be stored on the GPU", v.Type().Elem())
+	}
+
+	return v.Len() * int(v.Type().Elem().Size()), nil
+}
+
+// plainType reports whether a type is made of numbers only
+func plainType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Array:
+		return plainType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !plainType(t.Field(i).Type) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,308 @@
+package bgl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EncodeStd140 encodes a struct into the std140 layout of a uniform block.
+// Exported fields are encoded in order and mapped to GLSL types by their Go
+// type:
+//
+//	float32, float64, int32, uint32, bool   float, double, int, uint, bool
+//	[2]T, [3]T, [4]T of those scalars        vec2, vec3, vec4 (dvec, ivec, ...)
+//	[9]float32, [16]float32                  mat3, mat4 (column-major)
+//	structs                                  structs
+//	other arrays and slices                  arrays
+//
+// The `std140` field tag overrides the mapping: "-" skips the field, a matrix
+// type such as "mat2", "mat3x4" or "dmat4" encodes an array of floats as that
+// matrix and "array" encodes an array of 2 to 4 scalars as an array instead of
+// a vector. Tags of array fields apply to their elements.
+func EncodeStd140(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("std140: expected a struct, got %v", rv.Type())
+	}
+
+	var e std140
+	if err := e.encode(rv, ""); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+// std140 is a std140 encoder
+type std140 struct {
+	buf []byte
+}
+
+// pad appends zeros up to the next multiple of align
+func (e *std140) pad(align int) {
+	for len(e.buf)%align != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+// encode appends a value of the given tag
+func (e *std140) encode(v reflect.Value, tag string) error {
+	t := v.Type()
+
+	if s := std140Scalar(t.Kind()); s > 0 {
+		e.pad(s)
+		e.scalar(v)
+
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		align, err := std140Align(t, tag)
+		if err != nil {
+			return err
+		}
+
+		e.pad(align)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			ftag := f.Tag.Get("std140")
+			if f.PkgPath != "" || ftag == "-" {
+				continue
+			}
+
+			if err := e.encode(v.Field(i), ftag); err != nil {
+				return fmt.Errorf("field %v: %w", f.Name, err)
+			}
+		}
+		e.pad(align)
+
+		return nil
+
+	case reflect.Array, reflect.Slice:
+		cols, rows, err := std140Matrix(t, tag)
+		if err != nil {
+			return err
+		}
+
+		s := std140Scalar(t.Elem().Kind())
+
+		// columns are laid out like an array of vectors
+		if cols > 0 {
+			stride := roundUp(std140VecAlign(rows, s), 16)
+
+			e.pad(stride)
+			for c := 0; c < cols; c++ {
+				start := len(e.buf)
+				for r := 0; r < rows; r++ {
+					e.scalar(v.Index(c*rows + r))
+				}
+
+				e.buf = append(e.buf, make([]byte, start+stride-len(e.buf))...)
+			}
+
+			return nil
+		}
+
+		if std140IsVec(t, tag) {
+			e.pad(std140VecAlign(t.Len(), s))
+			for i := 0; i < t.Len(); i++ {
+				e.scalar(v.Index(i))
+			}
+
+			return nil
+		}
+
+		elemTag := tag
+		if tag == "array" {
+			elemTag = ""
+		}
+
+		elemAlign, err := std140Align(t.Elem(), elemTag)
+		if err != nil {
+			return err
+		}
+
+		align := roundUp(elemAlign, 16)
+
+		e.pad(align)
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i), elemTag); err != nil {
+				return err
+			}
+			e.pad(align)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("std140: unsupported type %v", t)
+}
+
+// scalar appends a scalar without alignment
+func (e *std140) scalar(v reflect.Value) {
+	var b [8]byte
+
+	switch v.Kind() {
+	case reflect.Float32:
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(v.Float())))
+		e.buf = append(e.buf, b[:4]...)
+	case reflect.Float64:
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v.Float()))
+		e.buf = append(e.buf, b[:8]...)
+	case reflect.Int32:
+		binary.LittleEndian.PutUint32(b[:], uint32(v.Int()))
+		e.buf = append(e.buf, b[:4]...)
+	case reflect.Uint32:
+		binary.LittleEndian.PutUint32(b[:], uint32(v.Uint()))
+		e.buf = append(e.buf, b[:4]...)
+	case reflect.Bool:
+		if v.Bool() {
+			b[0] = 1
+		}
+		e.buf = append(e.buf, b[:4]...)
+	}
+}
+
+// std140Align returns the base alignment of a type
+func std140Align(t reflect.Type, tag string) (int, error) {
+	if s := std140Scalar(t.Kind()); s > 0 {
+		return s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		align := 16
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			ftag := f.Tag.Get("std140")
+			if f.PkgPath != "" || ftag == "-" {
+				continue
+			}
+
+			a, err := std140Align(f.Type, ftag)
+			if err != nil {
+				return 0, fmt.Errorf("field %v: %w", f.Name, err)
+			}
+
+			if a > align {
+				align = a
+			}
+		}
+
+		return roundUp(align, 16), nil
+
+	case reflect.Array, reflect.Slice:
+		cols, rows, err := std140Matrix(t, tag)
+		if err != nil {
+			return 0, err
+		}
+
+		s := std140Scalar(t.Elem().Kind())
+		if cols > 0 {
+			return roundUp(std140VecAlign(rows, s), 16), nil
+		}
+
+		if std140IsVec(t, tag) {
+			return std140VecAlign(t.Len(), s), nil
+		}
+
+		if tag == "array" {
+			tag = ""
+		}
+
+		a, err := std140Align(t.Elem(), tag)
+		if err != nil {
+			return 0, err
+		}
+
+		return roundUp(a, 16), nil
+	}
+
+	return 0, fmt.Errorf("std140: unsupported type %v", t)
+}
+
+// std140Scalar returns the size of a scalar kind, 0 if not a scalar
+func std140Scalar(k reflect.Kind) int {
+	switch k {
+	case reflect.Float32, reflect.Int32, reflect.Uint32, reflect.Bool:
+		return 4
+	case reflect.Float64:
+		return 8
+	}
+
+	return 0
+}
+
+// std140VecAlign returns the base alignment of an n component vector of
+// scalars of size s
+func std140VecAlign(n, s int) int {
+	if n == 2 {
+		return 2 * s
+	}
+
+	return 4 * s
+}
+
+// std140IsVec reports whether an array type is encoded as a vector
+func std140IsVec(t reflect.Type, tag string) bool {
+	return t.Kind() == reflect.Array && tag != "array" &&
+		std140Scalar(t.Elem().Kind()) > 0 && t.Len() >= 2 && t.Len() <= 4
+}
+
+// std140Matrix returns the columns and rows of an array type encoded as a
+// matrix, zero if it is not a matrix
+func std140Matrix(t reflect.Type, tag string) (cols, rows int, err error) {
+	if t.Kind() != reflect.Array {
+		return 0, 0, nil
+	}
+
+	k := t.Elem().Kind()
+	if k != reflect.Float32 && k != reflect.Float64 {
+		return 0, 0, nil
+	}
+
+	name := strings.TrimPrefix(tag, "d")
+	if !strings.HasPrefix(name, "mat") {
+		if tag == "" {
+			switch t.Len() {
+			case 9:
+				return 3, 3, nil
+			case 16:
+				return 4, 4, nil
+			}
+		}
+
+		return 0, 0, nil
+	}
+
+	dims := strings.SplitN(strings.TrimPrefix(name, "mat"), "x", 2)
+	if len(dims) == 1 {
+		dims = append(dims, dims[0])
+	}
+
+	cols, err1 := strconv.Atoi(dims[0])
+	rows, err2 := strconv.Atoi(dims[1])
+	if err1 != nil || err2 != nil || cols < 2 || cols > 4 || rows < 2 || rows > 4 {
+		return 0, 0, fmt.Errorf("std140: invalid matrix type \"%v\"", tag)
+	}
+
+	if cols*rows != t.Len() {
+		return 0, 0, fmt.Errorf("std140: %v does not hold a %v", t, tag)
+	}
+
+	return cols, rows, nil
+}
+
+// roundUp rounds n up to a multiple of align
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
@@ -0,0 +1,256 @@
+package bgl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// word is an expected value at a byte offset of an encoded block
+type word struct {
+	off int
+	v   interface{} // float32, float64, uint32 or int32
+}
+
+// layout builds the expected encoding of size bytes, zero except for words
+func layout(size int, words ...word) []byte {
+	b := make([]byte, size)
+	for _, w := range words {
+		switch v := w.v.(type) {
+		case float32:
+			binary.LittleEndian.PutUint32(b[w.off:], math.Float32bits(v))
+		case float64:
+			binary.LittleEndian.PutUint64(b[w.off:], math.Float64bits(v))
+		case uint32:
+			binary.LittleEndian.PutUint32(b[w.off:], v)
+		case int32:
+			binary.LittleEndian.PutUint32(b[w.off:], uint32(v))
+		}
+	}
+
+	return b
+}
+
+// floats returns words of consecutive float32 values starting at off
+func floats(off int, vs ...float32) []word {
+	words := make([]word, len(vs))
+	for i, v := range vs {
+		words[i] = word{off + 4*i, v}
+	}
+
+	return words
+}
+
+func join(words ...[]word) []word {
+	var all []word
+	for _, w := range words {
+		all = append(all, w...)
+	}
+
+	return all
+}
+
+type std140Inner struct {
+	A float32
+	B [2]float32
+}
+
+func TestEncodeStd140(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want []byte
+	}{
+		{
+			"vec3 followed by float",
+			struct {
+				A [3]float32
+				B float32
+			}{[3]float32{1, 2, 3}, 4},
+			layout(16, floats(0, 1, 2, 3, 4)...),
+		},
+		{
+			"float followed by vec3",
+			struct {
+				A float32
+				B [3]float32
+			}{1, [3]float32{2, 3, 4}},
+			layout(32, join(floats(0, 1), floats(16, 2, 3, 4))...),
+		},
+		{
+			"float followed by vec2",
+			struct {
+				A float32
+				B [2]float32
+				C float32
+			}{1, [2]float32{2, 3}, 4},
+			layout(32, join(floats(0, 1), floats(8, 2, 3, 4))...),
+		},
+		{
+			"scalars",
+			struct {
+				A int32
+				B uint32
+				C bool
+				D bool
+				E float64
+			}{-1, 7, true, false, 2.5},
+			layout(32, word{0, int32(-1)}, word{4, uint32(7)}, word{8, uint32(1)}, word{16, float64(2.5)}),
+		},
+		{
+			"mat3",
+			struct {
+				A float32
+				M [9]float32
+				B float32
+			}{1, [9]float32{2, 3, 4, 5, 6, 7, 8, 9, 10}, 11},
+			layout(80, join(floats(0, 1), floats(16, 2, 3, 4), floats(32, 5, 6, 7), floats(48, 8, 9, 10), floats(64, 11))...),
+		},
+		{
+			"mat4",
+			struct {
+				M [16]float32
+			}{[16]float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}},
+			layout(64, floats(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16)...),
+		},
+		{
+			"mat2 tag",
+			struct {
+				M [4]float32 `std140:"mat2"`
+				A float32
+			}{[4]float32{1, 2, 3, 4}, 5},
+			layout(48, join(floats(0, 1, 2), floats(16, 3, 4), floats(32, 5))...),
+		},
+		{
+			"mat2x3 tag",
+			struct {
+				M [6]float32 `std140:"mat2x3"`
+			}{[6]float32{1, 2, 3, 4, 5, 6}},
+			layout(32, join(floats(0, 1, 2, 3), floats(16, 4, 5, 6))...),
+		},
+		{
+			"dmat2 tag",
+			struct {
+				A float32
+				M [4]float64 `std140:"dmat2"`
+			}{1, [4]float64{2, 3, 4, 5}},
+			layout(48, word{0, float32(1)}, word{16, 2.0}, word{24, 3.0}, word{32, 4.0}, word{40, 5.0}),
+		},
+		{
+			"dmat3 tag",
+			struct {
+				M [9]float64 `std140:"dmat3"`
+			}{[9]float64{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+			layout(96,
+				word{0, 1.0}, word{8, 2.0}, word{16, 3.0},
+				word{32, 4.0}, word{40, 5.0}, word{48, 6.0},
+				word{64, 7.0}, word{72, 8.0}, word{80, 9.0}),
+		},
+		{
+			"array tag",
+			struct {
+				A [3]float32 `std140:"array"`
+				B float32
+			}{[3]float32{1, 2, 3}, 4},
+			layout(64, join(floats(0, 1), floats(16, 2), floats(32, 3), floats(48, 4))...),
+		},
+		{
+			"array of scalars",
+			struct {
+				A [5]float32
+				B []int32
+			}{[5]float32{1, 2, 3, 4, 5}, []int32{6, 7}},
+			layout(112, join(floats(0, 1), floats(16, 2), floats(32, 3), floats(48, 4), floats(64, 5), []word{{80, int32(6)}, {96, int32(7)}})...),
+		},
+		{
+			"array of vec3",
+			struct {
+				A [2][3]float32
+			}{[2][3]float32{{1, 2, 3}, {4, 5, 6}}},
+			layout(32, join(floats(0, 1, 2, 3), floats(16, 4, 5, 6))...),
+		},
+		{
+			"array of mat2",
+			struct {
+				A [2][4]float32 `std140:"mat2"`
+			}{[2][4]float32{{1, 2, 3, 4}, {5, 6, 7, 8}}},
+			layout(64, join(floats(0, 1, 2), floats(16, 3, 4), floats(32, 5, 6), floats(48, 7, 8))...),
+		},
+		{
+			"array of structs",
+			struct {
+				S [2]std140Inner
+				C float32
+			}{[2]std140Inner{{1, [2]float32{2, 3}}, {4, [2]float32{5, 6}}}, 7},
+			layout(48, join(floats(0, 1), floats(8, 2, 3), floats(16, 4), floats(24, 5, 6), floats(32, 7))...),
+		},
+		{
+			"nested struct",
+			struct {
+				A float32
+				S std140Inner
+				B float32
+			}{1, std140Inner{2, [2]float32{3, 4}}, 5},
+			layout(48, join(floats(0, 1), floats(16, 2), floats(24, 3, 4), floats(32, 5))...),
+		},
+		{
+			"skipped and unexported fields",
+			struct {
+				A float32
+				B float32 `std140:"-"`
+				c float32
+				D float32
+			}{1, 2, 3, 4},
+			layout(16, floats(0, 1, 4)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeStd140(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got  % x\nwant % x", got, tt.want)
+			}
+
+			// pointers encode the same
+			ptr := reflect.New(reflect.TypeOf(tt.v))
+			ptr.Elem().Set(reflect.ValueOf(tt.v))
+			if enc, err := EncodeStd140(ptr.Interface()); err != nil || !bytes.Equal(enc, got) {
+				t.Errorf("pointer encodes to % x, %v", enc, err)
+			}
+		})
+	}
+}
+
+func TestEncodeStd140Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"not a struct", [4]float32{}, "expected a struct"},
+		{"unsupported field", struct{ S string }{}, "field S: std140: unsupported type string"},
+		{"invalid matrix tag", struct {
+			M [4]float32 `std140:"mat5"`
+		}{}, "invalid matrix type \"mat5\""},
+		{"wrong matrix size", struct {
+			M [6]float32 `std140:"mat3"`
+		}{}, "does not hold a mat3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EncodeStd140(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
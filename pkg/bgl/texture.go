@@ -15,11 +15,32 @@ const (
 	Nearest                 = Filter(gl.NEAREST)
 )
 
+// TextureFormat is the storage format of a texture or renderbuffer
+type TextureFormat struct {
+	Internal uint32 // sized internal format
+	Format   uint32 // pixel format of uploaded data
+	Type     uint32 // component type of uploaded data
+}
+
+var (
+	FormatRGBA8           = TextureFormat{gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE}
+	FormatRGBA16F         = TextureFormat{gl.RGBA16F, gl.RGBA, gl.HALF_FLOAT}
+	FormatRGBA32F         = TextureFormat{gl.RGBA32F, gl.RGBA, gl.FLOAT}
+	FormatRG16F           = TextureFormat{gl.RG16F, gl.RG, gl.HALF_FLOAT}
+	FormatR8              = TextureFormat{gl.R8, gl.RED, gl.UNSIGNED_BYTE}
+	FormatR32F            = TextureFormat{gl.R32F, gl.RED, gl.FLOAT}
+	FormatR32UI           = TextureFormat{gl.R32UI, gl.RED_INTEGER, gl.UNSIGNED_INT}
+	FormatDepth24         = TextureFormat{gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT}
+	FormatDepth32F        = TextureFormat{gl.DEPTH_COMPONENT32F, gl.DEPTH_COMPONENT, gl.FLOAT}
+	FormatDepth24Stencil8 = TextureFormat{gl.DEPTH24_STENCIL8, gl.DEPTH_STENCIL, gl.UNSIGNED_INT_24_8}
+)
+
 // Texture is an OpenGL texture.
 type Texture struct {
 	ID            uint32
 	width, height int
 	filter        int32
+	format        TextureFormat
 }
 
 // NewTexture creates a new texture with the specified width and height with some initial
@@ -31,6 +52,7 @@ func NewTexture(img *image.RGBA, filter Filter) *Texture {
 	tex := &Texture{
 		width:  width,
 		height: height,
+		format: FormatRGBA8,
 	}
 
 	gl.GenTextures(1, &tex.ID)
@@ -41,7 +63,7 @@ func NewTexture(img *image.RGBA, filter Filter) *Texture {
 	gl.TexImage2D(
 		gl.TEXTURE_2D,
 		0,
-		gl.RGBA,
+		int32(tex.format.Internal),
 		int32(width),
 		int32(height),
 		0,
@@ -66,9 +88,86 @@ func NewTexture(img *image.RGBA, filter Filter) *Texture {
 	return tex
 }
 
+// NewTextureFormat creates an empty texture of the given size and format, e.g.
+// as a framebuffer attachment. Its edges are clamped.
+func NewTextureFormat(width, height int, format TextureFormat, filter Filter) *Texture {
+	tex := &Texture{
+		format: format,
+	}
+
+	gl.GenTextures(1, &tex.ID)
+
+	tex.Bind()
+
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	tex.SetFilter(filter)
+
+	tex.Unbind()
+
+	tex.Resize(width, height)
+
+	runtime.SetFinalizer(tex, (*Texture).Delete)
+
+	return tex
+}
+
+// Resize reallocates the Texture with the given size, discarding its content
+func (t *Texture) Resize(width, height int) {
+	t.width = width
+	t.height = height
+
+	t.Bind()
+
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		int32(t.format.Internal),
+		int32(width),
+		int32(height),
+		0,
+		t.format.Format,
+		t.format.Type,
+		nil,
+	)
+
+	t.Unbind()
+}
+
+// Format returns the storage format of the Texture
+func (t *Texture) Format() TextureFormat {
+	return t.format
+}
+
 // Delete deletes the Texture.
 func (t *Texture) Delete() {
 	gl.DeleteTextures(1, &t.ID)
+	t.ID = 0
+
+	runtime.SetFinalizer(t, nil)
+}
+
+// SetImage replaces the content of the Texture with an image, resizing the
+// Texture to the size of the image.
+func (t *Texture) SetImage(img *image.RGBA) {
+	t.width = img.Rect.Max.X - img.Rect.Min.X
+	t.height = img.Rect.Max.Y - img.Rect.Min.Y
+
+	t.Bind()
+
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		int32(t.format.Internal),
+		int32(t.width),
+		int32(t.height),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(img.Pix),
+	)
+
+	t.Unbind()
 }
 
 // Width returns the width of the Texture in pixels.
@@ -0,0 +1,212 @@
+package bgl
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+// UniformBuffer is a uniform buffer object bound to a uniform block binding
+// point. Every program whose block is bound to the same point (see
+// Program.SetBlockBinding or "layout(binding = n)") reads the same data.
+type UniformBuffer struct {
+	Usage
+	ID      uint32
+	Binding uint32
+
+	size int
+}
+
+// NewUniformBuffer creates a uniform buffer for the given binding point
+func NewUniformBuffer(binding uint32) *UniformBuffer {
+	ub := &UniformBuffer{
+		Usage:   DynamicDraw,
+		Binding: binding,
+	}
+
+	gl.GenBuffers(1, &ub.ID)
+
+	runtime.SetFinalizer(ub, (*UniformBuffer).Delete)
+
+	return ub
+}
+
+// Bind binds the buffer to its binding point
+func (ub *UniformBuffer) Bind() {
+	gl.BindBufferBase(gl.UNIFORM_BUFFER, ub.Binding, ub.ID)
+}
+
+// SetBinding moves the buffer to another binding point
+func (ub *UniformBuffer) SetBinding(binding uint32) {
+	ub.Binding = binding
+
+	if ub.size > 0 {
+		ub.Bind()
+	}
+}
+
+// Len returns the size of the buffer in bytes
+func (ub *UniformBuffer) Len() int {
+	return ub.size
+}
+
+// SetData uploads raw block data, which must already be in the layout of the
+// block (see EncodeStd140), and binds the buffer to its binding point
+func (ub *UniformBuffer) SetData(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.UNIFORM_BUFFER, ub.ID)
+	if len(data) == ub.size {
+		gl.BufferSubData(gl.UNIFORM_BUFFER, 0, len(data), gl.Ptr(data))
+	} else {
+		gl.BufferData(gl.UNIFORM_BUFFER, len(data), gl.Ptr(data), uint32(ub.Usage))
+		ub.size = len(data)
+	}
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+
+	ub.Bind()
+}
+
+// Set encodes a struct in std140 layout (see EncodeStd140) and uploads it
+func (ub *UniformBuffer) Set(v interface{}) error {
+	data, err := EncodeStd140(v)
+	if err != nil {
+		return err
+	}
+
+	ub.SetData(data)
+
+	return nil
+}
+
+// Delete deletes the buffer
+func (ub *UniformBuffer) Delete() {
+	gl.DeleteBuffers(1, &ub.ID)
+	ub.ID = 0
+
+	runtime.SetFinalizer(ub, nil)
+}
+
+// UniformBlock is an active uniform block of a program as reported by OpenGL
+type UniformBlock struct {
+	Name    string
+	Index   uint32
+	Binding uint32
+	Size    int // minimum buffer size in bytes
+
+	Members []BlockMember
+}
+
+// BlockMember is a member of a uniform block. Its Attr has no location.
+type BlockMember struct {
+	Attr
+	Offset       int // offset in bytes from the start of the block
+	ArrayStride  int // bytes between array elements, 0 if not an array
+	MatrixStride int // bytes between matrix columns (or rows), 0 if not a matrix
+	RowMajor     bool
+}
+
+// Member returns the member with the given name
+func (b UniformBlock) Member(name string) (BlockMember, bool) {
+	for _, m := range b.Members {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return BlockMember{}, false
+}
+
+// findBlocks finds the uniform blocks in the program
+func (p *Program) findBlocks() {
+	active := uint32(p.getiv(gl.ACTIVE_UNIFORM_BLOCKS))
+	p.UniformBlocks = make([]UniformBlock, active)
+	for i := uint32(0); i < active; i++ {
+		var b [256]byte // name
+		gl.GetActiveUniformBlockName(p.ID, i, 256, nil, &b[0])
+
+		var size, binding, count int32
+		gl.GetActiveUniformBlockiv(p.ID, i, gl.UNIFORM_BLOCK_DATA_SIZE, &size)
+		gl.GetActiveUniformBlockiv(p.ID, i, gl.UNIFORM_BLOCK_BINDING, &binding)
+		gl.GetActiveUniformBlockiv(p.ID, i, gl.UNIFORM_BLOCK_ACTIVE_UNIFORMS, &count)
+
+		block := UniformBlock{
+			Name:    gl.GoStr(&b[0]),
+			Index:   i,
+			Binding: uint32(binding),
+			Size:    int(size),
+			Members: make([]BlockMember, count),
+		}
+
+		if count > 0 {
+			indices := make([]int32, count)
+			gl.GetActiveUniformBlockiv(p.ID, i, gl.UNIFORM_BLOCK_ACTIVE_UNIFORM_INDICES, &indices[0])
+
+			uindices := make([]uint32, count)
+			for j, index := range indices {
+				uindices[j] = uint32(index)
+			}
+
+			param := func(pname uint32) []int32 {
+				values := make([]int32, count)
+				gl.GetActiveUniformsiv(p.ID, count, &uindices[0], pname, &values[0])
+
+				return values
+			}
+
+			types := param(gl.UNIFORM_TYPE)
+			sizes := param(gl.UNIFORM_SIZE)
+			offsets := param(gl.UNIFORM_OFFSET)
+			arrayStrides := param(gl.UNIFORM_ARRAY_STRIDE)
+			matrixStrides := param(gl.UNIFORM_MATRIX_STRIDE)
+			rowMajor := param(gl.UNIFORM_IS_ROW_MAJOR)
+
+			for j, index := range uindices {
+				gl.GetActiveUniformName(p.ID, index, 256, nil, &b[0])
+
+				block.Members[j] = BlockMember{
+					Attr: Attr{
+						Name:  gl.GoStr(&b[0]),
+						Type:  AttrType(types[j]),
+						Loc:   -1,
+						Count: int(sizes[j]),
+					},
+					Offset:       int(offsets[j]),
+					ArrayStride:  int(arrayStrides[j]),
+					MatrixStride: int(matrixStrides[j]),
+					RowMajor:     rowMajor[j] != 0,
+				}
+			}
+		}
+
+		p.UniformBlocks[i] = block
+	}
+}
+
+// Block returns the uniform block with the given name
+func (p *Program) Block(name string) (UniformBlock, bool) {
+	for _, b := range p.UniformBlocks {
+		if b.Name == name {
+			return b, true
+		}
+	}
+
+	return UniformBlock{}, false
+}
+
+// SetBlockBinding binds the named uniform block to a binding point
+func (p *Program) SetBlockBinding(name string, binding uint32) error {
+	for i, b := range p.UniformBlocks {
+		if b.Name == name {
+			gl.UniformBlockBinding(p.ID, b.Index, binding)
+			p.UniformBlocks[i].Binding = binding
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("uniform block not found: %v", name)
+}
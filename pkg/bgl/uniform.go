@@ -0,0 +1,615 @@
+package bgl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+// Uniform returns the active uniform with the given name. Arrays are found by
+// their name with or without "[0]"; other elements (e.g. "lights[2]") are
+// looked up on first use and cached, counting the elements from there on.
+func (p *Program) Uniform(name string) (Attr, bool) {
+	if a, ok := p.uniforms[name]; ok {
+		return a, true
+	}
+
+	// element of an array
+	i := strings.LastIndexByte(name, '[')
+	if i < 0 || !strings.HasSuffix(name, "]") {
+		return Attr{}, false
+	}
+
+	base, ok := p.uniforms[name[:i]]
+	if !ok {
+		return Attr{}, false
+	}
+
+	n, err := strconv.Atoi(name[i+1 : len(name)-1])
+	if err != nil || n < 0 || n >= base.Count {
+		return Attr{}, false
+	}
+
+	a := base
+	a.Name = name
+	a.Loc = gl.GetUniformLocation(p.ID, gl.Str(name+"\x00"))
+	a.Count = base.Count - n
+
+	if p.uniforms == nil {
+		p.uniforms = map[string]Attr{}
+	}
+	p.uniforms[name] = a
+
+	return a, true
+}
+
+// uniform returns the location of a uniform after checking that it has one of
+// the given types and at least n elements
+func (p *Program) uniform(name string, n int, types ...AttrType) (int32, error) {
+	a, ok := p.Uniform(name)
+	if !ok {
+		return -1, fmt.Errorf("uniform not found: %v", name)
+	}
+
+	if a.Loc < 0 {
+		return -1, fmt.Errorf("uniform has no location (block member?): %v", name)
+	}
+
+	match := false
+	for _, t := range types {
+		if a.Type == t || (t == Int && a.Type.Opaque()) {
+			match = true
+			break
+		}
+	}
+	if !match {
+		return -1, fmt.Errorf("uniform \"%v\" is %v, not %v", name, a.Type, types[0])
+	}
+
+	if n > a.Count {
+		return -1, fmt.Errorf("uniform \"%v\" has %v elements, got %v", name, a.Count, n)
+	}
+
+	return a.Loc, nil
+}
+
+// SetUniform sets the value of the given uniform from a value of the matching
+// Go type: float32, float64, int32, uint32 or bool, fixed size arrays of them
+// for vectors and column-major matrices, or a slice of scalars for scalar
+// arrays.
+func (p *Program) SetUniform(name string, value interface{}) error {
+	a, ok := p.Uniform(name)
+	if !ok {
+		return fmt.Errorf("uniform not found: %v", name)
+	}
+
+	switch v := value.(type) {
+	case float32:
+		return p.SetFloat(name, v)
+	case []float32:
+		return p.SetFloat(name, v...)
+	case [2]float32:
+		return p.SetVec2f(name, v)
+	case [3]float32:
+		return p.SetVec3f(name, v)
+	case [4]float32:
+		if a.Type == Mat2f {
+			return p.SetMat2f(name, v)
+		}
+		return p.SetVec4f(name, v)
+	case [6]float32:
+		if a.Type == Mat3x2f {
+			return p.SetMat3x2f(name, v)
+		}
+		return p.SetMat2x3f(name, v)
+	case [8]float32:
+		if a.Type == Mat4x2f {
+			return p.SetMat4x2f(name, v)
+		}
+		return p.SetMat2x4f(name, v)
+	case [9]float32:
+		return p.SetMat3f(name, v)
+	case [12]float32:
+		if a.Type == Mat4x3f {
+			return p.SetMat4x3f(name, v)
+		}
+		return p.SetMat3x4f(name, v)
+	case [16]float32:
+		return p.SetMat4f(name, v)
+	case float64:
+		return p.SetDouble(name, v)
+	case []float64:
+		return p.SetDouble(name, v...)
+	case [2]float64:
+		return p.SetVec2d(name, v)
+	case [3]float64:
+		return p.SetVec3d(name, v)
+	case [4]float64:
+		if a.Type == Mat2d {
+			return p.SetMat2d(name, v)
+		}
+		return p.SetVec4d(name, v)
+	case [6]float64:
+		if a.Type == Mat3x2d {
+			return p.SetMat3x2d(name, v)
+		}
+		return p.SetMat2x3d(name, v)
+	case [8]float64:
+		if a.Type == Mat4x2d {
+			return p.SetMat4x2d(name, v)
+		}
+		return p.SetMat2x4d(name, v)
+	case [9]float64:
+		return p.SetMat3d(name, v)
+	case [12]float64:
+		if a.Type == Mat4x3d {
+			return p.SetMat4x3d(name, v)
+		}
+		return p.SetMat3x4d(name, v)
+	case [16]float64:
+		return p.SetMat4d(name, v)
+	case int:
+		return p.SetInt(name, int32(v))
+	case int32:
+		return p.SetInt(name, v)
+	case []int32:
+		return p.SetInt(name, v...)
+	case [2]int32:
+		return p.SetVec2i(name, v)
+	case [3]int32:
+		return p.SetVec3i(name, v)
+	case [4]int32:
+		return p.SetVec4i(name, v)
+	case uint32:
+		return p.SetUInt(name, v)
+	case []uint32:
+		return p.SetUInt(name, v...)
+	case [2]uint32:
+		return p.SetVec2ui(name, v)
+	case [3]uint32:
+		return p.SetVec3ui(name, v)
+	case [4]uint32:
+		return p.SetVec4ui(name, v)
+	case bool:
+		return p.SetBool(name, v)
+	case []bool:
+		return p.SetBool(name, v...)
+	}
+
+	return fmt.Errorf("unsupported uniform value type for \"%v\" (%v): %T", name, a.Type, value)
+}
+
+// SetBool sets a bool uniform or the elements of a bool array
+func (p *Program) SetBool(name string, v ...bool) error {
+	loc, err := p.uniform(name, len(v), Bool)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	iv := make([]int32, len(v))
+	for i, b := range v {
+		if b {
+			iv[i] = 1
+		}
+	}
+
+	gl.ProgramUniform1iv(p.ID, loc, int32(len(iv)), &iv[0])
+
+	return nil
+}
+
+// SetSampler sets a sampler or image uniform to a texture or image unit. It is
+// equivalent to SetInt.
+func (p *Program) SetSampler(name string, unit ...int32) error {
+	return p.SetInt(name, unit...)
+}
+
+// SetFloat sets a float uniform or the elements of an array
+func (p *Program) SetFloat(name string, v ...float32) error {
+	loc, err := p.uniform(name, len(v), Float)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform1fv(p.ID, loc, int32(len(v)), &v[0])
+
+	return nil
+}
+
+// SetVec2f sets a vec2 uniform or the elements of an array
+func (p *Program) SetVec2f(name string, v ...[2]float32) error {
+	loc, err := p.uniform(name, len(v), Vec2f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform2fv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec3f sets a vec3 uniform or the elements of an array
+func (p *Program) SetVec3f(name string, v ...[3]float32) error {
+	loc, err := p.uniform(name, len(v), Vec3f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform3fv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec4f sets a vec4 uniform or the elements of an array
+func (p *Program) SetVec4f(name string, v ...[4]float32) error {
+	loc, err := p.uniform(name, len(v), Vec4f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform4fv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetInt sets an int, bool, sampler or image uniform or the elements of an array
+func (p *Program) SetInt(name string, v ...int32) error {
+	loc, err := p.uniform(name, len(v), Int, Bool)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform1iv(p.ID, loc, int32(len(v)), &v[0])
+
+	return nil
+}
+
+// SetVec2i sets an ivec2 or bvec2 uniform or the elements of an array
+func (p *Program) SetVec2i(name string, v ...[2]int32) error {
+	loc, err := p.uniform(name, len(v), Vec2i, Vec2b)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform2iv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec3i sets an ivec3 or bvec3 uniform or the elements of an array
+func (p *Program) SetVec3i(name string, v ...[3]int32) error {
+	loc, err := p.uniform(name, len(v), Vec3i, Vec3b)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform3iv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec4i sets an ivec4 or bvec4 uniform or the elements of an array
+func (p *Program) SetVec4i(name string, v ...[4]int32) error {
+	loc, err := p.uniform(name, len(v), Vec4i, Vec4b)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform4iv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetUInt sets a uint or bool uniform or the elements of an array
+func (p *Program) SetUInt(name string, v ...uint32) error {
+	loc, err := p.uniform(name, len(v), UInt, Bool)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform1uiv(p.ID, loc, int32(len(v)), &v[0])
+
+	return nil
+}
+
+// SetVec2ui sets a uvec2 or bvec2 uniform or the elements of an array
+func (p *Program) SetVec2ui(name string, v ...[2]uint32) error {
+	loc, err := p.uniform(name, len(v), Vec2ui, Vec2b)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform2uiv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec3ui sets a uvec3 or bvec3 uniform or the elements of an array
+func (p *Program) SetVec3ui(name string, v ...[3]uint32) error {
+	loc, err := p.uniform(name, len(v), Vec3ui, Vec3b)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform3uiv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec4ui sets a uvec4 or bvec4 uniform or the elements of an array
+func (p *Program) SetVec4ui(name string, v ...[4]uint32) error {
+	loc, err := p.uniform(name, len(v), Vec4ui, Vec4b)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform4uiv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetDouble sets a double uniform or the elements of an array
+func (p *Program) SetDouble(name string, v ...float64) error {
+	loc, err := p.uniform(name, len(v), Double)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform1dv(p.ID, loc, int32(len(v)), &v[0])
+
+	return nil
+}
+
+// SetVec2d sets a dvec2 uniform or the elements of an array
+func (p *Program) SetVec2d(name string, v ...[2]float64) error {
+	loc, err := p.uniform(name, len(v), Vec2d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform2dv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec3d sets a dvec3 uniform or the elements of an array
+func (p *Program) SetVec3d(name string, v ...[3]float64) error {
+	loc, err := p.uniform(name, len(v), Vec3d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform3dv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetVec4d sets a dvec4 uniform or the elements of an array
+func (p *Program) SetVec4d(name string, v ...[4]float64) error {
+	loc, err := p.uniform(name, len(v), Vec4d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniform4dv(p.ID, loc, int32(len(v)), &v[0][0])
+
+	return nil
+}
+
+// SetMat2f sets a column-major mat2 uniform or the elements of an array
+func (p *Program) SetMat2f(name string, v ...[4]float32) error {
+	loc, err := p.uniform(name, len(v), Mat2f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix2fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat3f sets a column-major mat3 uniform or the elements of an array
+func (p *Program) SetMat3f(name string, v ...[9]float32) error {
+	loc, err := p.uniform(name, len(v), Mat3f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix3fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat4f sets a column-major mat4 uniform or the elements of an array
+func (p *Program) SetMat4f(name string, v ...[16]float32) error {
+	loc, err := p.uniform(name, len(v), Mat4f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix4fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat2x3f sets a column-major mat2x3 uniform or the elements of an array
+func (p *Program) SetMat2x3f(name string, v ...[6]float32) error {
+	loc, err := p.uniform(name, len(v), Mat2x3f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix2x3fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat2x4f sets a column-major mat2x4 uniform or the elements of an array
+func (p *Program) SetMat2x4f(name string, v ...[8]float32) error {
+	loc, err := p.uniform(name, len(v), Mat2x4f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix2x4fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat3x2f sets a column-major mat3x2 uniform or the elements of an array
+func (p *Program) SetMat3x2f(name string, v ...[6]float32) error {
+	loc, err := p.uniform(name, len(v), Mat3x2f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix3x2fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat3x4f sets a column-major mat3x4 uniform or the elements of an array
+func (p *Program) SetMat3x4f(name string, v ...[12]float32) error {
+	loc, err := p.uniform(name, len(v), Mat3x4f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix3x4fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat4x2f sets a column-major mat4x2 uniform or the elements of an array
+func (p *Program) SetMat4x2f(name string, v ...[8]float32) error {
+	loc, err := p.uniform(name, len(v), Mat4x2f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix4x2fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat4x3f sets a column-major mat4x3 uniform or the elements of an array
+func (p *Program) SetMat4x3f(name string, v ...[12]float32) error {
+	loc, err := p.uniform(name, len(v), Mat4x3f)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix4x3fv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat2d sets a column-major dmat2 uniform or the elements of an array
+func (p *Program) SetMat2d(name string, v ...[4]float64) error {
+	loc, err := p.uniform(name, len(v), Mat2d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix2dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat3d sets a column-major dmat3 uniform or the elements of an array
+func (p *Program) SetMat3d(name string, v ...[9]float64) error {
+	loc, err := p.uniform(name, len(v), Mat3d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix3dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat4d sets a column-major dmat4 uniform or the elements of an array
+func (p *Program) SetMat4d(name string, v ...[16]float64) error {
+	loc, err := p.uniform(name, len(v), Mat4d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix4dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat2x3d sets a column-major dmat2x3 uniform or the elements of an array
+func (p *Program) SetMat2x3d(name string, v ...[6]float64) error {
+	loc, err := p.uniform(name, len(v), Mat2x3d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix2x3dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat2x4d sets a column-major dmat2x4 uniform or the elements of an array
+func (p *Program) SetMat2x4d(name string, v ...[8]float64) error {
+	loc, err := p.uniform(name, len(v), Mat2x4d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix2x4dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat3x2d sets a column-major dmat3x2 uniform or the elements of an array
+func (p *Program) SetMat3x2d(name string, v ...[6]float64) error {
+	loc, err := p.uniform(name, len(v), Mat3x2d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix3x2dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat3x4d sets a column-major dmat3x4 uniform or the elements of an array
+func (p *Program) SetMat3x4d(name string, v ...[12]float64) error {
+	loc, err := p.uniform(name, len(v), Mat3x4d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix3x4dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat4x2d sets a column-major dmat4x2 uniform or the elements of an array
+func (p *Program) SetMat4x2d(name string, v ...[8]float64) error {
+	loc, err := p.uniform(name, len(v), Mat4x2d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix4x2dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
+
+// SetMat4x3d sets a column-major dmat4x3 uniform or the elements of an array
+func (p *Program) SetMat4x3d(name string, v ...[12]float64) error {
+	loc, err := p.uniform(name, len(v), Mat4x3d)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	gl.ProgramUniformMatrix4x3dv(p.ID, loc, int32(len(v)), false, &v[0][0])
+
+	return nil
+}
@@ -15,7 +15,19 @@ type VAO struct {
 	size int
 }
 
+// AttrBuffer pairs a VBO with the format of the attributes stored in it
+type AttrBuffer struct {
+	*VBO
+	AttrFormat
+
+	// Divisor is the number of instances drawn before the attributes advance.
+	// Zero advances the attributes once per vertex.
+	Divisor uint32
+}
+
 // NewVAO creates a new VAO
+//
+// NOTE: the VBO holding the data must be bound before calling NewVAO
 func NewVAO(format AttrFormat) *VAO {
 	vao := &VAO{
 		AttrFormat: format,
@@ -25,20 +37,31 @@ func NewVAO(format AttrFormat) *VAO {
 	gl.GenVertexArrays(1, &vao.ID)
 
 	vao.Bind()
+	vao.attribPointers(format, 0)
+	vao.Unbind()
+
+	runtime.SetFinalizer(vao, (*VAO).Delete)
+
+	return vao
+}
 
-	offsets := vao.AttrFormat.Offsets()
-	for i, attr := range vao.AttrFormat {
-		gl.VertexAttribPointer(
-			uint32(attr.Loc),
-			int32(attr.Size()),
-			gl.FLOAT,
-			attr.Normalized,
-			int32(vao.AttrFormat.Len()),
-			gl.PtrOffset(offsets[i]),
-		)
-		gl.EnableVertexAttribArray(uint32(attr.Loc))
+// NewInstancedVAO creates a new VAO sourcing attributes from multiple buffers,
+// each of which may advance per vertex or per instance
+func NewInstancedVAO(buffers ...AttrBuffer) *VAO {
+	vao := &VAO{
+		AttrFormat: AttrFormat{},
+		DrawMode:   Triangles,
 	}
 
+	gl.GenVertexArrays(1, &vao.ID)
+
+	vao.Bind()
+	for _, b := range buffers {
+		vao.AttrFormat = append(vao.AttrFormat, b.AttrFormat...)
+
+		b.VBO.Bind()
+		vao.attribPointers(b.AttrFormat, b.Divisor)
+	}
 	vao.Unbind()
 
 	runtime.SetFinalizer(vao, (*VAO).Delete)
@@ -46,6 +69,46 @@ func NewVAO(format AttrFormat) *VAO {
 	return vao
 }
 
+// attribPointers describes the attributes of the currently bound buffer
+func (vao *VAO) attribPointers(format AttrFormat, divisor uint32) {
+	stride := int32(format.Len())
+
+	offsets := format.Offsets()
+	for i, attr := range format {
+		if attr.Loc < 0 {
+			// attribute is not used by the program but still occupies space
+			continue
+		}
+
+		// matrices occupy one location per column
+		cols := attr.Type.Cols()
+		size := int32(attr.Size() / cols)
+		clen := attr.Len() / cols
+		locs := 1
+		if clen > 16 {
+			// double vectors larger than 16 bytes take two locations
+			locs = 2
+		}
+
+		for c := 0; c < cols; c++ {
+			loc := uint32(int(attr.Loc) + c*locs)
+			ptr := gl.PtrOffset(offsets[i] + c*clen)
+
+			switch attr.Type.Base() {
+			case gl.INT, gl.UNSIGNED_INT:
+				gl.VertexAttribIPointer(loc, size, attr.Type.Base(), stride, ptr)
+			case gl.DOUBLE:
+				gl.VertexAttribLPointer(loc, size, attr.Type.Base(), stride, ptr)
+			default:
+				gl.VertexAttribPointer(loc, size, gl.FLOAT, attr.Normalized, stride, ptr)
+			}
+
+			gl.EnableVertexAttribArray(loc)
+			gl.VertexAttribDivisor(loc, divisor)
+		}
+	}
+}
+
 // Bind binds the VAO
 func (vao *VAO) Bind() {
 	gl.BindVertexArray(vao.ID)
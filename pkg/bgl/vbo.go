@@ -45,6 +45,26 @@ func (vbo *VBO) Draw() {
 	gl.DrawArrays(uint32(vbo.DrawMode), 0, int32(vbo.Size()))
 }
 
+// DrawRange draws count vertices of the VBO starting at the vertex first
+func (vbo *VBO) DrawRange(first, count int) {
+	if count == 0 {
+		return
+	}
+
+	vbo.Bind()
+	gl.DrawArrays(uint32(vbo.DrawMode), int32(first), int32(count))
+}
+
+// DrawInstanced draws count vertices of the VBO once for each instance
+func (vbo *VBO) DrawInstanced(count, instances int) {
+	if count == 0 || instances == 0 {
+		return
+	}
+
+	vbo.Bind()
+	gl.DrawArraysInstanced(uint32(vbo.DrawMode), 0, int32(count), int32(instances))
+}
+
 // Unbind unbinds the VBO
 func (vbo *VBO) Unbind() {
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
@@ -0,0 +1,229 @@
+package blit
+
+import "time"
+
+// AnimMode is the way an animation continues after its last frame
+type AnimMode int
+
+const (
+	AnimLoop     AnimMode = iota // restart from the first frame
+	AnimOnce                     // stop on the last frame
+	AnimPingPong                 // play backwards to the first frame, then forwards again
+)
+
+// Frame is a single animation frame: a rect of the spritesheet texture shown
+// for a duration. Frames with a duration of zero or less are held until the
+// player is seeked or restarted.
+type Frame struct {
+	Rect
+	Trim     Trim // placement in the untrimmed source, zero if not trimmed
+	Duration time.Duration
+}
+
+// Animation is a sequence of frames of one spritesheet texture
+type Animation struct {
+	Name   string
+	Frames []Frame
+	Mode   AnimMode
+}
+
+// Duration returns the total duration of one pass through the frames
+func (a *Animation) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range a.Frames {
+		d += f.Duration
+	}
+
+	return d
+}
+
+// AnimPlayer plays an Animation, setting the rect of the current frame on a
+// sprite. It is driven by elapsed time through Update.
+type AnimPlayer struct {
+	Anim   *Animation
+	Sprite *Sprite // sprite showing the current frame, may be nil
+
+	Speed  float32 // playback speed multiplier, 1 is normal speed
+	Paused bool
+
+	OnFrame  func(frame int) // called when the current frame changes
+	OnFinish func()          // called when a once animation ends or a loop completes
+
+	frame   int
+	elapsed time.Duration // time spent on the current frame
+	dir     int           // frame step, -1 while playing backwards in ping-pong mode
+	done    bool
+}
+
+// NewAnimPlayer creates a player showing the first frame of the animation on
+// the sprite (may be nil)
+func NewAnimPlayer(anim *Animation, sprite *Sprite) *AnimPlayer {
+	p := &AnimPlayer{
+		Sprite: sprite,
+		Speed:  1,
+	}
+
+	p.Play(anim)
+
+	return p
+}
+
+// Play switches to an animation and restarts playback. Playing the current
+// animation restarts it.
+func (p *AnimPlayer) Play(anim *Animation) {
+	p.Anim = anim
+	p.Paused = false
+	p.Seek(0)
+}
+
+// Pause pauses playback
+func (p *AnimPlayer) Pause() {
+	p.Paused = true
+}
+
+// Resume resumes playback
+func (p *AnimPlayer) Resume() {
+	p.Paused = false
+}
+
+// Seek jumps to the start of a frame, clamped to the frames of the animation
+func (p *AnimPlayer) Seek(frame int) {
+	p.elapsed = 0
+	p.dir = 1
+	p.done = false
+
+	if p.Anim == nil || len(p.Anim.Frames) == 0 {
+		p.frame = 0
+		return
+	}
+
+	if frame < 0 {
+		frame = 0
+	}
+	if frame >= len(p.Anim.Frames) {
+		frame = len(p.Anim.Frames) - 1
+	}
+
+	p.setFrame(frame)
+}
+
+// SeekTime jumps to a point in time from the start of the animation. Times
+// past the end wrap in loop and ping-pong mode and finish once animations.
+func (p *AnimPlayer) SeekTime(t time.Duration) {
+	p.Seek(0)
+	p.advance(t)
+}
+
+// Frame returns the index of the current frame
+func (p *AnimPlayer) Frame() int {
+	return p.frame
+}
+
+// Rect returns the texture rect of the current frame
+func (p *AnimPlayer) Rect() Rect {
+	if p.Anim == nil || len(p.Anim.Frames) == 0 {
+		return Rect{}
+	}
+
+	return p.Anim.Frames[p.frame].Rect
+}
+
+// Done reports whether a once animation has finished
+func (p *AnimPlayer) Done() bool {
+	return p.done
+}
+
+// Update advances playback by dt scaled by Speed
+func (p *AnimPlayer) Update(dt time.Duration) {
+	if p.Paused || p.Speed <= 0 {
+		return
+	}
+
+	p.advance(time.Duration(float64(dt) * float64(p.Speed)))
+}
+
+// advance moves playback forward by dt, stepping over as many frames as
+// needed
+func (p *AnimPlayer) advance(dt time.Duration) {
+	if p.done || p.Anim == nil || len(p.Anim.Frames) == 0 {
+		return
+	}
+
+	p.elapsed += dt
+
+	for {
+		d := p.Anim.Frames[p.frame].Duration
+		if d <= 0 || p.elapsed < d {
+			return
+		}
+
+		p.elapsed -= d
+		if !p.step() {
+			p.elapsed = 0
+			return
+		}
+	}
+}
+
+// step moves to the next frame according to the mode. It reports false once a
+// once animation has ended.
+func (p *AnimPlayer) step() bool {
+	n := len(p.Anim.Frames)
+	next := p.frame + p.dir
+
+	switch p.Anim.Mode {
+	case AnimOnce:
+		if next >= n {
+			p.done = true
+			p.finish()
+			return false
+		}
+	case AnimPingPong:
+		if n == 1 {
+			// nothing to reverse, a single frame loops
+			next = 0
+			p.finish()
+			break
+		}
+
+		if next >= n || next < 0 {
+			p.dir = -p.dir
+			next = p.frame + p.dir
+		}
+
+		if next == 0 && p.dir < 0 {
+			// back at the start
+			p.finish()
+		}
+	default:
+		if next >= n {
+			next = 0
+			p.finish()
+		}
+	}
+
+	p.setFrame(next)
+
+	return true
+}
+
+// setFrame shows a frame on the sprite and reports changes
+func (p *AnimPlayer) setFrame(frame int) {
+	changed := frame != p.frame
+	p.frame = frame
+
+	if p.Sprite != nil {
+		p.Sprite.SetRect(p.Anim.Frames[frame].Rect)
+	}
+
+	if changed && p.OnFrame != nil {
+		p.OnFrame(frame)
+	}
+}
+
+// finish calls OnFinish if set
+func (p *AnimPlayer) finish() {
+	if p.OnFinish != nil {
+		p.OnFinish()
+	}
+}
@@ -0,0 +1,48 @@
+package blit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnimPlayerModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     AnimMode
+		frames   int
+		want     []int // frame after each step
+		finishes int
+	}{
+		{"loop", AnimLoop, 3, []int{1, 2, 0, 1, 2, 0, 1}, 2},
+		{"once", AnimOnce, 3, []int{1, 2, 2, 2}, 1},
+		{"ping-pong", AnimPingPong, 3, []int{1, 2, 1, 0, 1, 2, 1, 0}, 2},
+		{"ping-pong two frames", AnimPingPong, 2, []int{1, 0, 1, 0}, 2},
+		{"loop single frame", AnimLoop, 1, []int{0, 0, 0, 0}, 4},
+		{"ping-pong single frame", AnimPingPong, 1, []int{0, 0, 0, 0}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim := &Animation{Mode: tt.mode}
+			for i := 0; i < tt.frames; i++ {
+				anim.Frames = append(anim.Frames, Frame{Duration: 100 * time.Millisecond})
+			}
+
+			finishes := 0
+			p := NewAnimPlayer(anim, nil)
+			p.OnFinish = func() { finishes++ }
+
+			for i, want := range tt.want {
+				p.Update(100 * time.Millisecond)
+
+				if got := p.Frame(); got != want {
+					t.Fatalf("step %v: frame %v, want %v", i, got, want)
+				}
+			}
+
+			if finishes != tt.finishes {
+				t.Errorf("OnFinish called %v times, want %v", finishes, tt.finishes)
+			}
+		})
+	}
+}
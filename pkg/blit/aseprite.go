@@ -0,0 +1,304 @@
+package blit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+// asepriteRect is a rectangle as exported by Aseprite
+type asepriteRect struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	W float32 `json:"w"`
+	H float32 `json:"h"`
+}
+
+func (r asepriteRect) rect() Rect {
+	return Rect{r.X, r.Y, r.W, r.H}
+}
+
+// asepriteFrame is a single frame in either the hash or the array export
+type asepriteFrame struct {
+	Filename         string       `json:"filename"`
+	Frame            asepriteRect `json:"frame"`
+	Rotated          bool         `json:"rotated"`
+	Trimmed          bool         `json:"trimmed"`
+	SpriteSourceSize asepriteRect `json:"spriteSourceSize"`
+	SourceSize       asepriteRect `json:"sourceSize"`
+	Duration         int          `json:"duration"` // milliseconds
+}
+
+// asepriteSheet is the JSON export of an Aseprite spritesheet
+type asepriteSheet struct {
+	Frames json.RawMessage `json:"frames"` // object (hash) or array
+	Meta   struct {
+		App       string `json:"app"`
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"`
+		} `json:"frameTags"`
+		Slices []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+			Keys  []struct {
+				Frame  int           `json:"frame"`
+				Bounds asepriteRect  `json:"bounds"`
+				Center *asepriteRect `json:"center"`
+				Pivot  *struct {
+					X float32 `json:"x"`
+					Y float32 `json:"y"`
+				} `json:"pivot"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+// isAseprite reports whether a JSON description looks like an Aseprite export:
+// it has "frames" and a "meta" object naming the app or image. Descriptions
+// with sprites named "frames" and "meta" do not match, as their values are
+// arrays.
+func isAseprite(desc []byte) bool {
+	var probe struct {
+		Frames json.RawMessage `json:"frames"`
+		Meta   *struct {
+			App   string `json:"app"`
+			Image string `json:"image"`
+		} `json:"meta"`
+	}
+
+	if err := json.Unmarshal(desc, &probe); err != nil {
+		return false
+	}
+
+	return len(probe.Frames) > 0 && probe.Meta != nil && (probe.Meta.App != "" || probe.Meta.Image != "")
+}
+
+// GenAsepriteSpritesheet creates a spritesheet from an image and its Aseprite
+// JSON export (hash or array variant).
+//
+// Every frame becomes a sprite named by its filename. Frame tags become
+// animations: "reverse" tags play their frames backwards, "pingpong" tags play
+// in AnimPingPong mode and tags repeating exactly once play in AnimOnce mode;
+// other repeat counts loop indefinitely.
+func GenAsepriteSpritesheet(img *image.RGBA, desc []byte) (*Spritesheet, error) {
+	ss, err := genAseprite(desc, img.Bounds().Dx(), img.Bounds().Dy())
+	if err != nil {
+		return nil, err
+	}
+
+	ss.Texture = bgl.NewTexture(rebase(img), ss.Filter)
+
+	return ss, nil
+}
+
+// genAseprite creates a spritesheet without texture for an image of the given
+// size
+func genAseprite(desc []byte, w, h int) (*Spritesheet, error) {
+	var sheet asepriteSheet
+	if err := json.Unmarshal(desc, &sheet); err != nil {
+		return nil, fmt.Errorf("invalid aseprite json: %v", err)
+	}
+
+	frames, err := asepriteFrames(sheet.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := NewSpritesheet()
+
+	var diags Diagnostics
+	var sprites []SpriteDesc
+	index := map[string]int{}
+	for i, f := range frames {
+		if f.Rotated {
+			diags.errorf(f.Filename, "rotated frames are not supported")
+		}
+
+		frame := Frame{
+			Rect:     f.Frame.rect(),
+			Duration: time.Duration(f.Duration) * time.Millisecond,
+		}
+
+		sprite := SpriteDesc{
+			Name: f.Filename,
+			Rect: frame.Rect,
+		}
+
+		if f.Trimmed {
+			frame.Trim = Trim{
+				Offset: Vec{f.SpriteSourceSize.X, f.SpriteSourceSize.Y},
+				Size:   Vec{f.SourceSize.W, f.SourceSize.H},
+			}
+			sprite.Trim = &frame.Trim
+		}
+
+		if j, ok := index[f.Filename]; ok {
+			diags.warnf(f.Filename, "duplicate frame name (frame %v), the last frame is used as sprite", i)
+			sprites[j] = sprite
+		} else {
+			index[f.Filename] = len(sprites)
+			sprites = append(sprites, sprite)
+		}
+
+		ss.Frames = append(ss.Frames, frame)
+	}
+
+	checkSprites(&diags, sprites, w, h)
+
+	for _, s := range sprites {
+		ss.Sprites[s.Name] = s.Rect
+
+		if s.Trim != nil {
+			ss.Trims[s.Name] = *s.Trim
+		}
+	}
+
+	for _, tag := range sheet.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(ss.Frames) || tag.From > tag.To {
+			diags.errorf(tag.Name, "frame tag out of range: %v-%v", tag.From, tag.To)
+			continue
+		}
+
+		anim := &Animation{
+			Name:   tag.Name,
+			Frames: append([]Frame(nil), ss.Frames[tag.From:tag.To+1]...),
+		}
+
+		switch tag.Direction {
+		case "", "forward":
+		case "reverse":
+			reverseFrames(anim.Frames)
+		case "pingpong":
+			anim.Mode = AnimPingPong
+		case "pingpong_reverse":
+			reverseFrames(anim.Frames)
+			anim.Mode = AnimPingPong
+		default:
+			diags.errorf(tag.Name, "frame tag has unknown direction \"%v\"", tag.Direction)
+			continue
+		}
+
+		if tag.Repeat == "1" && anim.Mode == AnimLoop {
+			anim.Mode = AnimOnce
+		}
+
+		ss.Animations[tag.Name] = anim
+	}
+
+	for _, s := range sheet.Meta.Slices {
+		slice := Slice{
+			Name:  s.Name,
+			Color: s.Color,
+		}
+
+		for _, k := range s.Keys {
+			key := SliceKey{
+				Frame:  k.Frame,
+				Bounds: k.Bounds.rect(),
+			}
+
+			if k.Center != nil {
+				key.Center = k.Center.rect()
+			}
+			if k.Pivot != nil {
+				key.Pivot = Vec{k.Pivot.X, k.Pivot.Y}
+			}
+
+			slice.Keys = append(slice.Keys, key)
+		}
+
+		ss.Slices[s.Name] = slice
+	}
+
+	if err := diags.Err(); err != nil {
+		return nil, err
+	}
+
+	ss.Warnings = diags
+
+	return ss, nil
+}
+
+// asepriteFrames decodes the frames of either export variant in sheet order
+func asepriteFrames(raw json.RawMessage) ([]asepriteFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("aseprite json has no frames")
+	}
+
+	// array variant, names are stored in each frame
+	if raw[0] == '[' {
+		var frames []asepriteFrame
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, fmt.Errorf("invalid aseprite frames: %v", err)
+		}
+
+		return frames, nil
+	}
+
+	// hash variant, frame indices follow the order of the keys
+	keys, values, err := objectEntries(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aseprite frames: %v", err)
+	}
+
+	frames := make([]asepriteFrame, len(keys))
+	for i := range keys {
+		if err := json.Unmarshal(values[i], &frames[i]); err != nil {
+			return nil, fmt.Errorf("invalid aseprite frame \"%v\": %v", keys[i], err)
+		}
+
+		frames[i].Filename = keys[i]
+	}
+
+	return frames, nil
+}
+
+// objectEntries returns the keys and raw values of a JSON object in document
+// order
+func objectEntries(raw []byte) ([]string, []json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, nil, fmt.Errorf("expected object")
+	}
+
+	var keys []string
+	var values []json.RawMessage
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, tok.(string))
+		values = append(values, v)
+	}
+
+	return keys, values, nil
+}
+
+// reverseFrames reverses a slice of frames in place
+func reverseFrames(frames []Frame) {
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+}
@@ -0,0 +1,108 @@
+package blit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAsepriteDiagnostics(t *testing.T) {
+	desc := []byte(`{
+		"frames": [
+			{"filename": "a", "frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "duration": 100},
+			{"filename": "b", "frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "rotated": true, "duration": 100},
+			{"filename": "c", "frame": {"x": 60, "y": 0, "w": 16, "h": 16}, "duration": 100},
+			{"filename": "d", "frame": {"x": 0, "y": 16, "w": 8, "h": 8}, "trimmed": true,
+				"spriteSourceSize": {"x": 12, "y": 0, "w": 8, "h": 8}, "sourceSize": {"w": 16, "h": 16}, "duration": 100}
+		],
+		"meta": {
+			"frameTags": [
+				{"name": "walk", "from": 0, "to": 9, "direction": "forward"},
+				{"name": "idle", "from": 0, "to": 1, "direction": "sideways"}
+			]
+		}
+	}`)
+
+	_, err := genAseprite(desc, 64, 32)
+
+	var diags Diagnostics
+	if !errors.As(err, &diags) {
+		t.Fatalf("got %v, want Diagnostics", err)
+	}
+
+	want := map[string]bool{"b": true, "c": true, "d": true, "walk": true, "idle": true}
+	for _, d := range diags.Errors() {
+		if !want[d.Name] {
+			t.Errorf("unexpected error %v", d)
+		}
+		delete(want, d.Name)
+	}
+
+	for name := range want {
+		t.Errorf("no error reported for \"%v\"", name)
+	}
+}
+
+func TestAsepriteWarnings(t *testing.T) {
+	desc := []byte(`{
+		"frames": [
+			{"filename": "a", "frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "duration": 100},
+			{"filename": "b", "frame": {"x": 8, "y": 0, "w": 16, "h": 16}, "duration": 100},
+			{"filename": "a", "frame": {"x": 32, "y": 0, "w": 16, "h": 16}, "duration": 100},
+			{"filename": "c", "frame": {"x": 40, "y": 0, "w": 16, "h": 16}, "duration": 100}
+		],
+		"meta": {}
+	}`)
+
+	ss, err := genAseprite(desc, 64, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ss.Frames) != 4 {
+		t.Errorf("got %v frames, want 4", len(ss.Frames))
+	}
+
+	// the duplicate replaces the first "a", which no longer overlaps "b"
+	if r := ss.Sprites["a"]; r.X() != 32 {
+		t.Errorf("sprite \"a\" at %v, want the last frame", r)
+	}
+
+	want := []string{
+		`warning: "a": duplicate frame name (frame 2), the last frame is used as sprite`,
+		`warning: "a": overlaps "c"`,
+	}
+
+	if len(ss.Warnings) != len(want) {
+		t.Fatalf("warnings:\n%v\nwant:\n%v", ss.Warnings.Error(), want)
+	}
+	for i, d := range ss.Warnings {
+		if d.String() != want[i] {
+			t.Errorf("warning %v = %v, want %v", i, d, want[i])
+		}
+	}
+}
+
+func TestIsAseprite(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+		want bool
+	}{
+		{"hash", `{"frames": {"a.png": {}}, "meta": {"app": "https://www.aseprite.org/", "image": "a.png"}}`, true},
+		{"array", `{"frames": [{"filename": "a"}], "meta": {"app": "https://www.aseprite.org/"}}`, true},
+		{"image only", `{"frames": [], "meta": {"image": "a.png"}}`, true},
+		{"no app or image", `{"frames": [], "meta": {"frameTags": []}}`, false},
+		{"empty meta", `{"frames": [], "meta": {}}`, false},
+		{"no meta", `{"frames": []}`, false},
+		{"no frames", `{"meta": {"app": "https://www.aseprite.org/"}}`, false},
+		{"sprites named frames and meta", `{"frames": [0, 0, 16, 16], "meta": [16, 0, 16, 16]}`, false},
+		{"sprites and grid", `{"frames": [0, 0, 16, 16], "grid": {"width": 16, "height": 16}}`, false},
+		{"invalid", `{"frames": `, false},
+	}
+
+	for _, tt := range tests {
+		if got := isAseprite([]byte(tt.desc)); got != tt.want {
+			t.Errorf("%v: isAseprite = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
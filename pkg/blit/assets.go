@@ -0,0 +1,549 @@
+package blit
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"log"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+// AssetKind is the type of an Asset
+type AssetKind int
+
+const (
+	AssetTexture AssetKind = iota
+	AssetSpritesheet
+	AssetProgram
+	AssetFont
+)
+
+func (k AssetKind) String() string {
+	switch k {
+	case AssetTexture:
+		return "texture"
+	case AssetSpritesheet:
+		return "spritesheet"
+	case AssetProgram:
+		return "program"
+	case AssetFont:
+		return "font"
+	}
+
+	return fmt.Sprintf("AssetKind(%d)", int(k))
+}
+
+// Asset is a cached, reference counted asset of an Assets manager. Its value
+// is available once Ready reports true.
+type Asset struct {
+	Kind  AssetKind
+	Names []string // file names the asset was loaded from
+
+	key  string
+	refs int
+	deps []*Asset
+
+	// set by the worker before decoded is closed
+	decoded   chan struct{}
+	decodeErr error
+	upload    func() (interface{}, error) // creates the GPU objects, main thread only
+	reload    func(a *Asset) error        // reloads the files into the GPU objects
+	img       *image.RGBA
+	w, h      int
+
+	// set on the main thread
+	done  bool
+	err   error
+	value interface{}
+}
+
+// Ready reports whether the asset finished loading, successfully or not
+func (a *Asset) Ready() bool {
+	return a.done
+}
+
+// Err returns the error that occurred while loading the asset, if any
+func (a *Asset) Err() error {
+	return a.err
+}
+
+// Texture returns the texture of a texture asset, nil if not ready
+func (a *Asset) Texture() *bgl.Texture {
+	t, _ := a.value.(*bgl.Texture)
+	return t
+}
+
+// Spritesheet returns the spritesheet of a spritesheet asset, nil if not ready
+func (a *Asset) Spritesheet() *Spritesheet {
+	ss, _ := a.value.(*Spritesheet)
+	return ss
+}
+
+// Program returns the program of a program asset, nil if not ready
+func (a *Asset) Program() *bgl.Program {
+	p, _ := a.value.(*bgl.Program)
+	return p
+}
+
+// Font returns the font of a font asset, nil if not ready
+func (a *Asset) Font() *Font {
+	f, _ := a.value.(*Font)
+	return f
+}
+
+// Assets loads textures, spritesheets, shader programs and fonts from a file
+// system and caches them by file name (and size for fonts), so that loading
+// the same file twice shares one GPU object. Assets are reference counted:
+// every Load call must be matched by a Release.
+//
+// Files are read and decoded on worker goroutines. The GPU objects are created
+// on the main thread by Process (or Wait), which should be called once per
+// frame while loading. Assets must only be used from the main thread.
+type Assets struct {
+	FS     fs.FS
+	Filter bgl.Filter // texture filter of newly loaded textures
+
+	// OnReload is called after a watched asset was reloaded. If nil, reload
+	// errors are logged.
+	OnReload func(a *Asset, err error)
+
+	assets  map[string]*Asset
+	workers chan struct{}
+	watcher *Watcher
+
+	mu      sync.Mutex
+	pending []*Asset // decoded assets awaiting upload
+}
+
+// NewAssets creates an asset manager loading from fsys
+func NewAssets(fsys fs.FS) *Assets {
+	return &Assets{
+		FS:      fsys,
+		Filter:  bgl.Nearest,
+		assets:  map[string]*Asset{},
+		workers: make(chan struct{}, runtime.NumCPU()),
+	}
+}
+
+// acquire returns the cached asset loaded from names, taking a reference, or
+// creates and starts loading it with decode. Assets loaded from the same files
+// with different options are told apart by variant.
+func (m *Assets) acquire(kind AssetKind, names []string, variant string, deps []*Asset, decode, reload func(a *Asset) error) *Asset {
+	key := kind.String() + ":" + strings.Join(names, "|")
+	if variant != "" {
+		key += "@" + variant
+	}
+
+	if a, ok := m.assets[key]; ok {
+		a.refs++
+
+		// the dependencies already hold their own reference
+		for _, d := range deps {
+			m.Release(d)
+		}
+
+		return a
+	}
+
+	a := &Asset{
+		Kind:    kind,
+		Names:   names,
+		key:     key,
+		refs:    1,
+		deps:    deps,
+		decoded: make(chan struct{}),
+		reload:  reload,
+	}
+	m.assets[key] = a
+
+	if m.watcher != nil {
+		for _, name := range names {
+			m.watcher.Add(name)
+		}
+	}
+
+	go func() {
+		// wait for dependencies before taking a worker so that they can
+		// never be starved of one
+		for _, d := range deps {
+			<-d.decoded
+		}
+
+		m.workers <- struct{}{}
+		a.decodeErr = decode(a)
+		<-m.workers
+
+		close(a.decoded)
+
+		m.mu.Lock()
+		m.pending = append(m.pending, a)
+		m.mu.Unlock()
+	}()
+
+	return a
+}
+
+// LoadTexture starts loading an image into a texture
+func (m *Assets) LoadTexture(name string) *Asset {
+	filter := m.Filter
+
+	return m.acquire(AssetTexture, []string{name}, "", nil, func(a *Asset) error {
+		img, err := LoadImageFS(m.FS, name)
+		if err != nil {
+			return err
+		}
+
+		a.img = img
+		a.w, a.h = img.Bounds().Dx(), img.Bounds().Dy()
+
+		a.upload = func() (interface{}, error) {
+			tex := bgl.NewTexture(a.img, filter)
+			a.img = nil
+
+			return tex, nil
+		}
+
+		return nil
+	}, func(a *Asset) error {
+		img, err := LoadImageFS(m.FS, name)
+		if err != nil {
+			return err
+		}
+
+		a.Texture().SetImage(img)
+		a.w, a.h = img.Bounds().Dx(), img.Bounds().Dy()
+
+		return nil
+	})
+}
+
+// LoadSpritesheet starts loading a spritesheet image and its JSON description
+// (see LoadSpritesheetFS). The image is loaded as a texture asset, so sheets
+// and textures of the same image share it.
+func (m *Assets) LoadSpritesheet(imgName, descName string) *Asset {
+	tex := m.LoadTexture(imgName)
+
+	return m.acquire(AssetSpritesheet, []string{imgName, descName}, "", []*Asset{tex}, func(a *Asset) error {
+		if tex.decodeErr != nil {
+			return tex.decodeErr
+		}
+
+		desc, err := fs.ReadFile(m.FS, descName)
+		if err != nil {
+			return err
+		}
+
+		ss, err := parseSpritesheet(desc, descName, tex.w, tex.h)
+		if err != nil {
+			return err
+		}
+
+		a.upload = func() (interface{}, error) {
+			m.finish(tex)
+			if tex.err != nil {
+				return nil, tex.err
+			}
+
+			ss.Texture = tex.Texture()
+
+			return ss, nil
+		}
+
+		return nil
+	}, func(a *Asset) error {
+		desc, err := fs.ReadFile(m.FS, descName)
+		if err != nil {
+			return err
+		}
+
+		ss, err := parseSpritesheet(desc, descName, tex.w, tex.h)
+		if err != nil {
+			return err
+		}
+
+		old := a.Spritesheet()
+		ss.Texture = old.Texture
+		ss.Filter = old.Filter
+		*old = *ss
+
+		return nil
+	})
+}
+
+// LoadProgram starts loading a program from shader sources (see
+// bgl.LoadProgramFS)
+func (m *Assets) LoadProgram(names ...string) *Asset {
+	return m.acquire(AssetProgram, names, "", nil, func(a *Asset) error {
+		shaders := make([]*bgl.Shader, 0, len(names))
+		for _, name := range names {
+			s, err := bgl.LoadShaderFS(m.FS, name)
+			if err != nil {
+				return err
+			}
+
+			shaders = append(shaders, s)
+		}
+
+		a.upload = func() (interface{}, error) {
+			p, err := bgl.NewProgram(shaders)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", strings.Join(names, ", "), err)
+			}
+
+			return p, nil
+		}
+
+		return nil
+	}, func(a *Asset) error {
+		shaders := make([]*bgl.Shader, 0, len(names))
+		for _, name := range names {
+			s, err := bgl.LoadShaderFS(m.FS, name)
+			if err != nil {
+				return err
+			}
+
+			shaders = append(shaders, s)
+		}
+
+		if err := a.Program().Reload(shaders); err != nil {
+			return fmt.Errorf("%v: %w", strings.Join(names, ", "), err)
+		}
+
+		return nil
+	})
+}
+
+// LoadFont starts loading a font rasterized at size pixels per em with the
+// default runes (see GenFont). The glyphs are rasterized on a worker.
+func (m *Assets) LoadFont(name string, size float32) *Asset {
+	filter := m.Filter
+
+	return m.acquire(AssetFont, []string{name}, fmt.Sprint(size), nil, func(a *Asset) error {
+		data, err := fs.ReadFile(m.FS, name)
+		if err != nil {
+			return err
+		}
+
+		f, img, err := genFont(data, size, "")
+		if err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+
+		a.img = img
+
+		a.upload = func() (interface{}, error) {
+			f.Filter = filter
+			f.Texture = bgl.NewTexture(a.img, filter)
+			a.img = nil
+
+			return f, nil
+		}
+
+		return nil
+	}, func(a *Asset) error {
+		data, err := fs.ReadFile(m.FS, name)
+		if err != nil {
+			return err
+		}
+
+		f, img, err := genFont(data, size, "")
+		if err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+
+		// keep the texture and spritesheet, which may be referenced directly
+		old := a.Font()
+		old.Texture.SetImage(img)
+
+		f.Texture = old.Texture
+		f.Filter = old.Filter
+		*old.Spritesheet = *f.Spritesheet
+		f.Spritesheet = old.Spritesheet
+		*old = *f
+
+		return nil
+	})
+}
+
+// finish creates the GPU objects of a decoded asset
+func (m *Assets) finish(a *Asset) {
+	if a.done {
+		return
+	}
+	a.done = true
+
+	// released while loading
+	if a.refs == 0 {
+		a.img = nil
+		return
+	}
+
+	if a.decodeErr != nil {
+		a.err = a.decodeErr
+		return
+	}
+
+	a.value, a.err = a.upload()
+	a.upload = nil
+}
+
+// Process creates the GPU objects of all assets decoded since the last call
+// and returns how many were finished. Call it once per frame from the main
+// thread.
+func (m *Assets) Process() int {
+	if m.watcher != nil {
+		m.reloadChanged(m.watcher.Poll())
+	}
+
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = nil
+	m.mu.Unlock()
+
+	n := 0
+	for _, a := range pending {
+		if !a.done {
+			m.finish(a)
+			n++
+		}
+	}
+
+	return n
+}
+
+// Wait blocks until the asset finished loading and returns its error
+func (m *Assets) Wait(a *Asset) error {
+	for _, d := range a.deps {
+		m.Wait(d)
+	}
+
+	<-a.decoded
+	m.finish(a)
+
+	return a.err
+}
+
+// Progress returns how many of the cached assets finished loading
+func (m *Assets) Progress() (done, total int) {
+	for _, a := range m.assets {
+		if a.done {
+			done++
+		}
+	}
+
+	return done, len(m.assets)
+}
+
+// Loading reports whether any asset is still loading
+func (m *Assets) Loading() bool {
+	done, total := m.Progress()
+
+	return done < total
+}
+
+// Release drops a reference to an asset. The GPU objects of an asset are
+// deleted once its last reference is released; assets still loading are
+// discarded when they finish.
+func (m *Assets) Release(a *Asset) {
+	if a.refs <= 0 {
+		return
+	}
+
+	a.refs--
+	if a.refs > 0 {
+		return
+	}
+
+	delete(m.assets, a.key)
+
+	if m.watcher != nil {
+		for _, name := range a.Names {
+			m.watcher.Remove(name)
+		}
+	}
+
+	switch v := a.value.(type) {
+	case *bgl.Texture:
+		v.Delete()
+	case *bgl.Program:
+		v.Delete()
+	case *Font:
+		v.Texture.Delete()
+	}
+	a.value = nil
+
+	// a spritesheet's texture belongs to its texture asset
+	for _, d := range a.deps {
+		m.Release(d)
+	}
+}
+
+// Watch enables hot reloading: the files of all assets are polled for changes
+// at most once per interval by Process, and modified assets are reloaded into
+// their existing GPU objects. Programs that fail to compile keep their old
+// version.
+func (m *Assets) Watch(interval time.Duration) {
+	if m.watcher != nil {
+		m.watcher.Interval = interval
+		return
+	}
+
+	m.watcher = NewWatcher(m.FS, interval)
+	for _, a := range m.assets {
+		for _, name := range a.Names {
+			m.watcher.Add(name)
+		}
+	}
+}
+
+// Unwatch disables hot reloading
+func (m *Assets) Unwatch() {
+	m.watcher = nil
+}
+
+// reloadChanged reloads the loaded assets using any of the changed files
+func (m *Assets) reloadChanged(changed []string) {
+	if len(changed) == 0 {
+		return
+	}
+
+	files := map[string]bool{}
+	for _, name := range changed {
+		files[name] = true
+	}
+
+	var reload []*Asset
+	for _, a := range m.assets {
+		if !a.done || a.value == nil {
+			continue
+		}
+
+		for _, name := range a.Names {
+			if files[name] {
+				reload = append(reload, a)
+				break
+			}
+		}
+	}
+
+	// textures first, spritesheets are parsed against their new size
+	sort.Slice(reload, func(i, j int) bool {
+		return reload[i].Kind < reload[j].Kind
+	})
+
+	for _, a := range reload {
+		err := a.reload(a)
+
+		if m.OnReload != nil {
+			m.OnReload(a, err)
+		} else if err != nil {
+			log.Printf("failed to reload %v %v: %v", a.Kind, strings.Join(a.Names, ", "), err)
+		}
+	}
+}
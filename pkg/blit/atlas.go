@@ -0,0 +1,38 @@
+package blit
+
+import (
+	"image"
+
+	"github.com/octalide/blit/pkg/atlas"
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+// GenAtlasSpritesheet uploads a packed atlas page and creates a spritesheet
+// for it
+func GenAtlasSpritesheet(page *atlas.Page) *Spritesheet {
+	ss := NewSpritesheet()
+
+	for name, r := range page.Sprites {
+		ss.Sprites[name] = atlasRect(r)
+	}
+	for name, t := range page.Trims {
+		ss.Trims[name] = Trim{
+			Offset: Vec{float32(t.Offset.X), float32(t.Offset.Y)},
+			Size:   Vec{float32(t.Size.X), float32(t.Size.Y)},
+		}
+	}
+
+	ss.Texture = bgl.NewTexture(page.Image, ss.Filter)
+
+	return ss
+}
+
+// atlasRect converts an atlas rectangle to a Rect
+func atlasRect(r image.Rectangle) Rect {
+	return Rect{
+		float32(r.Min.X),
+		float32(r.Min.Y),
+		float32(r.Dx()),
+		float32(r.Dy()),
+	}
+}
@@ -0,0 +1,193 @@
+package blit
+
+import (
+	"image/color"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+const (
+	batchVertexSize = 9 // x, y, z, u, v, r, g, b, a
+	batchQuadSize   = 6 // vertices per quad
+)
+
+// BatchStats describes the work done (or pending) in a batch
+type BatchStats struct {
+	DrawCalls int // number of draw calls (one per run of quads sharing texture and program)
+	Vertices  int // number of vertices submitted
+	Sprites   int // number of quads submitted
+}
+
+// batchKey identifies a group of quads that can be drawn in one call
+type batchKey struct {
+	shader *bgl.Program
+	tex    *bgl.Texture
+}
+
+// batchGroup is a run of consecutive vertices sharing a batchKey
+type batchGroup struct {
+	batchKey
+	first, count int // in vertices
+}
+
+// Batch collects sprites into a single streaming vertex buffer and draws them
+// with one draw call per run of quads sharing a texture and program.
+//
+// Quads are drawn in the order they were added, so overlapping sprites keep
+// their painter's order. Switching the texture or program starts a new draw
+// call; add quads grouped by texture to keep the number of calls low.
+//
+// Vertices are transformed on the CPU, so programs drawn through a batch must
+// use the vertex layout of bgl.BatchVert ("pos", "tex" and "col").
+type Batch struct {
+	vbo  *bgl.VBO
+	vaos map[*bgl.Program]*bgl.VAO
+
+	groups []batchGroup
+	data   []float32
+	dirty  bool // data changed since the last upload
+
+	stats BatchStats
+}
+
+// NewBatch creates a new empty batch. GPU buffers are created on the first
+// call to Flush.
+func NewBatch() *Batch {
+	return &Batch{
+		vaos: map[*bgl.Program]*bgl.VAO{},
+	}
+}
+
+// Add adds a visible sprite to the batch using its shader, texture, rect,
+// transform and mask
+func (b *Batch) Add(s *Sprite) {
+	if !s.Visible {
+		return
+	}
+
+	b.AddQuad(s.shader, s.Tex, s.Rect, s.Mat(), s.Mask)
+}
+
+// AddQuad adds a unit quad transformed by m, textured with the given rect of
+// tex and tinted by mask
+func (b *Batch) AddQuad(shader *bgl.Program, tex *bgl.Texture, rect Rect, m Mat, mask color.RGBA) {
+	key := batchKey{shader, tex}
+	b.dirty = true
+
+	// extend the last group or start a new one, keeping submission order
+	if n := len(b.groups); n > 0 && b.groups[n-1].batchKey == key {
+		b.groups[n-1].count += batchQuadSize
+	} else {
+		b.groups = append(b.groups, batchGroup{
+			batchKey: key,
+			first:    len(b.data) / batchVertexSize,
+			count:    batchQuadSize,
+		})
+	}
+
+	// corners in the same order as quadDefault
+	corners := [batchQuadSize][4]float32{
+		{-0.5, +0.5, rect.X(), rect.Y()},                       // top left
+		{-0.5, -0.5, rect.X(), rect.Y() + rect.H()},            // bottom left
+		{+0.5, -0.5, rect.X() + rect.W(), rect.Y() + rect.H()}, // bottom right
+		{+0.5, +0.5, rect.X() + rect.W(), rect.Y()},            // top right
+		{-0.5, +0.5, rect.X(), rect.Y()},                       // top left
+		{+0.5, -0.5, rect.X() + rect.W(), rect.Y() + rect.H()}, // bottom right
+	}
+
+	r := float32(mask.R) / 255.0
+	gr := float32(mask.G) / 255.0
+	bl := float32(mask.B) / 255.0
+	a := float32(mask.A) / 255.0
+
+	for _, c := range corners {
+		p := Vec{c[0], c[1], 0, 1}.Mat(m)
+		uv := tex.UV(c[2], c[3])
+
+		b.data = append(b.data, p[0], p[1], p[2], uv[0], uv[1], r, gr, bl, a)
+	}
+}
+
+// Pending returns the stats the batch would report if flushed now
+func (b *Batch) Pending() BatchStats {
+	n := len(b.data) / batchVertexSize
+
+	return BatchStats{
+		DrawCalls: len(b.groups),
+		Vertices:  n,
+		Sprites:   n / batchQuadSize,
+	}
+}
+
+// Stats returns the stats of the last draw or flush
+func (b *Batch) Stats() BatchStats {
+	return b.stats
+}
+
+// Clear discards all pending quads without drawing them
+func (b *Batch) Clear() {
+	b.groups = b.groups[:0]
+	b.data = b.data[:0]
+	b.dirty = true
+}
+
+// Flush draws all pending quads and clears the batch
+func (b *Batch) Flush() {
+	b.Draw()
+	b.Clear()
+}
+
+// Draw draws all pending quads with one draw call per group without clearing
+// them, so static geometry can be built once and drawn every frame. The quads
+// are uploaded in one buffer update, and only if they changed since the last
+// draw.
+func (b *Batch) Draw() {
+	b.stats = b.Pending()
+	if b.stats.DrawCalls == 0 {
+		return
+	}
+
+	if b.vbo == nil {
+		b.vbo = bgl.NewVBO()
+		b.vbo.Usage = bgl.StreamDraw
+	}
+
+	if b.dirty {
+		b.vbo.SetData(b.data)
+		b.dirty = false
+	}
+
+	for _, g := range b.groups {
+		vao := b.vao(g.shader)
+
+		g.shader.Bind()
+		g.tex.Bind()
+		vao.Bind()
+		b.vbo.DrawRange(g.first, g.count)
+		vao.Unbind()
+		g.tex.Unbind()
+		g.shader.Unbind()
+	}
+
+	b.vbo.Unbind()
+}
+
+// vao returns the VAO describing the batch vertex layout for the given program
+func (b *Batch) vao(shader *bgl.Program) *bgl.VAO {
+	if vao, ok := b.vaos[shader]; ok {
+		return vao
+	}
+
+	format := bgl.AttrFormat{
+		{Name: "pos", Type: bgl.Vec3f},
+		{Name: "tex", Type: bgl.Vec2f},
+		{Name: "col", Type: bgl.Vec4f},
+	}.Locate(shader)
+
+	// NOTE: VBO must be bound before the VAO is created
+	b.vbo.Bind()
+	vao := bgl.NewVAO(format)
+	b.vaos[shader] = vao
+
+	return vao
+}
@@ -0,0 +1,103 @@
+package blit
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+func TestBatchGroups(t *testing.T) {
+	progA, progB := &bgl.Program{}, &bgl.Program{}
+	texA, texB := &bgl.Texture{}, &bgl.Texture{}
+
+	type quad struct {
+		prog *bgl.Program
+		tex  *bgl.Texture
+	}
+
+	tests := []struct {
+		name  string
+		quads []quad
+		want  BatchStats
+	}{
+		{"empty", nil, BatchStats{}},
+		{"single", []quad{{progA, texA}}, BatchStats{1, 6, 1}},
+		{"same texture", []quad{{progA, texA}, {progA, texA}, {progA, texA}}, BatchStats{1, 18, 3}},
+		{"grouped textures", []quad{{progA, texA}, {progA, texA}, {progA, texB}, {progA, texB}}, BatchStats{2, 24, 4}},
+		{"interleaved textures", []quad{{progA, texA}, {progA, texB}, {progA, texA}}, BatchStats{3, 18, 3}},
+		{"program change", []quad{{progA, texA}, {progB, texA}, {progB, texA}}, BatchStats{2, 18, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBatch()
+			for _, q := range tt.quads {
+				b.AddQuad(q.prog, q.tex, Rect{0, 0, 1, 1}, Ident(), color.RGBA{255, 255, 255, 255})
+			}
+
+			if got := b.Pending(); got != tt.want {
+				t.Errorf("Pending() = %+v, want %+v", got, tt.want)
+			}
+
+			// groups are contiguous and in submission order
+			next := 0
+			for i, g := range b.groups {
+				if g.first != next {
+					t.Errorf("group %v starts at %v, want %v", i, g.first, next)
+				}
+				if g.batchKey != (batchKey{tt.quads[next/batchQuadSize].prog, tt.quads[next/batchQuadSize].tex}) {
+					t.Errorf("group %v has the key of a later quad", i)
+				}
+				next += g.count
+			}
+			if next != tt.want.Vertices {
+				t.Errorf("groups cover %v vertices, want %v", next, tt.want.Vertices)
+			}
+
+			b.Clear()
+			if got := b.Pending(); got != (BatchStats{}) {
+				t.Errorf("Pending() after Clear = %+v, want none", got)
+			}
+		})
+	}
+}
+
+func TestBatchVertices(t *testing.T) {
+	b := NewBatch()
+	m := Ident().Pos(Vec{10, 20, 3})
+	b.AddQuad(&bgl.Program{}, &bgl.Texture{}, Rect{0, 0, 1, 1}, m, color.RGBA{255, 0, 51, 255})
+
+	want := [][3]float32{
+		{9.5, 20.5, 3},
+		{9.5, 19.5, 3},
+		{10.5, 19.5, 3},
+		{10.5, 20.5, 3},
+		{9.5, 20.5, 3},
+		{10.5, 19.5, 3},
+	}
+
+	if len(b.data) != len(want)*batchVertexSize {
+		t.Fatalf("got %v floats, want %v", len(b.data), len(want)*batchVertexSize)
+	}
+
+	for i, w := range want {
+		v := b.data[i*batchVertexSize : (i+1)*batchVertexSize]
+		if v[0] != w[0] || v[1] != w[1] || v[2] != w[2] {
+			t.Errorf("vertex %v at %v, want %v", i, v[:3], w)
+		}
+		if v[5] != 1 || v[6] != 0 || v[7] != 0.2 || v[8] != 1 {
+			t.Errorf("vertex %v color %v, want [1 0 0.2 1]", i, v[5:])
+		}
+	}
+}
+
+func TestBatchFlushEmpty(t *testing.T) {
+	// flushing nothing must not touch GL
+	b := NewBatch()
+	b.Flush()
+
+	if got := b.Stats(); got != (BatchStats{}) {
+		t.Errorf("Stats() = %+v, want none", got)
+	}
+}
@@ -1,15 +1,35 @@
 package blit
 
 import (
+	"encoding/binary"
+	"math"
+
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/octalide/blit/pkg/bgl"
 )
 
+// Projection is the kind of projection used by a camera.
+type Projection int
+
+const (
+	ProjPerspective Projection = iota // perspective projection using FOV
+	ProjOrtho                         // orthographic projection using UnitsPerPixel and Zoom
+)
+
 // Cam is a camera.
 type Cam struct {
 	*Orienter
+	Projection
 	FOV      float32
-	Viewport Rect
+	Viewport Rect    // viewport in window coordinates, the current viewport is used if empty
+	Aspect   float32 // aspect ratio, derived from the viewport if zero
+	Near     float32 // near clip plane
+	Far      float32 // far clip plane
+
+	UnitsPerPixel float32 // world units covered by one screen pixel at a zoom of 1 (ortho only)
+	Zoom          int     // integer zoom factor (ortho only)
+	MaxZoom       int     // maximum zoom factor, zero for no limit (ortho only)
+	Snap          bool    // snap the view to the pixel grid (ortho only)
 }
 
 func NewCam() Cam {
@@ -18,35 +38,287 @@ func NewCam() Cam {
 		Orienter: &Orienter{
 			Vec: Vec{0, 0, 1},
 		},
+		Near:          0.1,
+		Far:           100,
+		UnitsPerPixel: 1,
+		Zoom:          1,
+	}
+}
+
+// NewOrthoCam creates a pixel-perfect orthographic camera where one screen
+// pixel covers the given number of world units at a zoom of 1.
+func NewOrthoCam(unitsPerPixel float32) Cam {
+	c := NewCam()
+	c.Projection = ProjOrtho
+	c.UnitsPerPixel = unitsPerPixel
+	c.Snap = true
+
+	return c
+}
+
+// viewport returns the camera viewport, falling back to the current viewport
+// if none is set.
+func (c Cam) viewport() Rect {
+	if c.Viewport.W() > 0 && c.Viewport.H() > 0 {
+		return c.Viewport
+	}
+
+	return Viewport()
+}
+
+// aspect returns the aspect ratio of the camera
+func (c Cam) aspect() float32 {
+	if c.Aspect > 0 {
+		return c.Aspect
+	}
+
+	vp := c.viewport()
+
+	return vp.W() / vp.H()
+}
+
+// PixelSize returns the world units covered by one screen pixel (ortho only).
+func (c Cam) PixelSize() float32 {
+	zoom := c.Zoom
+	if zoom < 1 {
+		zoom = 1
 	}
+
+	return c.UnitsPerPixel / float32(zoom)
+}
+
+// SetZoom sets the integer zoom factor, clamped to [1, MaxZoom].
+func (c *Cam) SetZoom(zoom int) {
+	if c.MaxZoom > 0 && zoom > c.MaxZoom {
+		zoom = c.MaxZoom
+	}
+	if zoom < 1 {
+		zoom = 1
+	}
+
+	c.Zoom = zoom
+}
+
+// ZoomBy changes the integer zoom factor by the given number of steps.
+func (c *Cam) ZoomBy(steps int) {
+	c.SetZoom(c.Zoom + steps)
+}
+
+// ZoomAt zooms by the given number of steps while keeping the world position
+// under the given window coordinates (on the plane at z) in place.
+// Orthographic cameras change their integer zoom, perspective cameras move a
+// fifth of the way towards that position per step.
+func (c *Cam) ZoomAt(screen Vec, steps int, z float32) {
+	before, ok := c.Pick(screen, z)
+	if !ok {
+		return
+	}
+
+	if c.Projection == ProjOrtho {
+		c.ZoomBy(steps)
+
+		if after, ok := c.Pick(screen, z); ok {
+			c.Pan(before.Sub(after))
+		}
+
+		return
+	}
+
+	// moving along the ray through the cursor keeps the cursor in place
+	t := 1 - float32(math.Pow(0.8, float64(steps)))
+	c.Pan(before.Sub(c.Pos()).Scl(t))
 }
 
 // Proj returns the projection matrix.
 func (c Cam) Proj() Mat {
-	return Perspective(mgl32.DegToRad(c.FOV), bgl.Aspect(), float32(0.1), float32(100))
+	vp := c.viewport()
+
+	if c.Projection == ProjOrtho {
+		// keep whole pixels on each side of the center so that pixel edges
+		// line up with odd viewport sizes
+		px := c.PixelSize()
+		left := -float32(int(vp.W()/2)) * px
+		bottom := -float32(int(vp.H()/2)) * px
+
+		return Ortho(left, left+vp.W()*px, bottom, bottom+vp.H()*px, c.Near, c.Far)
+	}
+
+	return Perspective(mgl32.DegToRad(c.FOV), c.aspect(), c.Near, c.Far)
 }
 
-// View returns the view matrix.
+// View returns the view matrix. The camera looks along its local -z axis.
 func (c Cam) View() Mat {
-	eye := c.Vec
+	m := c.Orienter.Mat()
 
-	center := c.Vec
-	center[2] = 0
+	if c.Projection == ProjOrtho && c.Snap {
+		px := float64(c.PixelSize())
+		m[12] = float32(math.Round(float64(m[12])/px) * px)
+		m[13] = float32(math.Round(float64(m[13])/px) * px)
+	}
 
-	up := Vec{0, 1, 0}
+	return m.Inv()
+}
 
-	return LookAt(eye, center, up)
+// CamBlock is the layout of the "Camera" uniform block of the stock shaders
+type CamBlock struct {
+	View Mat
+	Proj Mat
 }
 
-// Use sets the matrices in the given shader using the uniforms "proj" and "view"
-func (c Cam) Use(s *bgl.Program) {
-	s.Bind()
-	s.SetUniform("view", c.View().F())
-	s.SetUniform("proj", c.Proj().F())
-	s.Unbind()
+// std140 encodes the block in std140 layout. Both members are mat4, so the
+// block is the two matrices back to back without padding.
+func (b CamBlock) std140() []byte {
+	data := make([]byte, 2*len(b.View)*4)
+	for i, m := range []Mat{b.View, b.Proj} {
+		for j, f := range m {
+			binary.LittleEndian.PutUint32(data[(i*len(m)+j)*4:], math.Float32bits(f))
+		}
+	}
+
+	return data
+}
+
+// camBuffer is the uniform buffer shared by all programs using the camera block
+var camBuffer *bgl.UniformBuffer
+
+// Apply uploads the camera matrices to the shared "Camera" uniform block
+// (bound at bgl.CameraBinding), updating every program using it at once
+func (c Cam) Apply() {
+	if camBuffer == nil {
+		camBuffer = bgl.NewUniformBuffer(bgl.CameraBinding)
+	}
+
+	camBuffer.SetData(CamBlock{c.View(), c.Proj()}.std140())
+}
+
+// Use applies the camera block (see Apply) and, for shaders declaring them
+// outside of the block, sets the uniforms "view" and "proj". Shaders reading
+// the matrices from neither fail, as they would draw without the camera.
+func (c Cam) Use(s *bgl.Program) error {
+	c.Apply()
+
+	if _, ok := s.Block("Camera"); ok {
+		return nil
+	}
+
+	if err := s.SetMat4f("view", c.View().F()); err != nil {
+		return err
+	}
+
+	return s.SetMat4f("proj", c.Proj().F())
+}
+
+// Render restricts drawing to the camera viewport (both viewport and scissor)
+// while fn runs and restores the previous viewport, scissor box and scissor
+// test afterwards. This allows several cameras to draw into one frame
+// (split-screen, minimaps) and renders to nest. The camera block is applied
+// before fn runs.
+func (c Cam) Render(fn func()) {
+	prev := bgl.Viewport()
+	prevScissor := bgl.Scissor()
+	scissor := bgl.ScissorTest()
+	vp := c.viewport()
+
+	bgl.EnableScissor()
+	bgl.SetBounds(int(vp.X()), int(vp.Y()), int(vp.W()), int(vp.H()))
+
+	c.Apply()
+
+	fn()
+
+	bgl.SetBounds(int(prev[0]), int(prev[1]), int(prev[2]), int(prev[3]))
+	bgl.SetScissor(int(prevScissor[0]), int(prevScissor[1]), int(prevScissor[2]), int(prevScissor[3]))
+	if !scissor {
+		bgl.DisableScissor()
+	}
 }
 
 // Pan moves the camera by the given vector.
 func (c *Cam) Pan(v Vec) {
 	c.Vec = c.Vec.Add(v)
 }
+
+// Yaw rotates the camera counter-clockwise around its local y axis.
+func (c *Cam) Yaw(rad float32) {
+	c.Rotate(QuatAxisAngle(Vec{0, 1, 0}, rad))
+}
+
+// Pitch rotates the camera counter-clockwise around its local x axis.
+func (c *Cam) Pitch(rad float32) {
+	c.Rotate(QuatAxisAngle(Vec{1, 0, 0}, rad))
+}
+
+// Roll rotates the camera counter-clockwise around its local z axis.
+func (c *Cam) Roll(rad float32) {
+	c.Rotate(QuatAxisAngle(Vec{0, 0, 1}, rad))
+}
+
+// Forward returns the direction the camera is looking in world space.
+func (c Cam) Forward() Vec {
+	return c.WorldOrientation().Rotate(Vec{0, 0, -1})
+}
+
+// Up returns the up direction of the camera in world space.
+func (c Cam) Up() Vec {
+	return c.WorldOrientation().Rotate(Vec{0, 1, 0})
+}
+
+// Right returns the right direction of the camera in world space.
+func (c Cam) Right() Vec {
+	return c.WorldOrientation().Rotate(Vec{1, 0, 0})
+}
+
+// Project maps a world position to window coordinates (origin bottom left, as
+// used by viewports). The z component of the result is the depth in [0, 1].
+func (c Cam) Project(world Vec) Vec {
+	vp := c.viewport()
+	ndc := c.Proj().Mul(c.View()).Transform(world)
+
+	return Vec{
+		vp.X() + (ndc[0]+1)/2*vp.W(),
+		vp.Y() + (ndc[1]+1)/2*vp.H(),
+		(ndc[2] + 1) / 2,
+	}
+}
+
+// Unproject maps window coordinates (origin bottom left) and a depth in [0, 1]
+// to a world position. A depth of 0 lies on the near plane, 1 on the far plane.
+func (c Cam) Unproject(screen Vec, depth float32) Vec {
+	vp := c.viewport()
+	ndc := Vec{
+		(screen[0]-vp.X())/vp.W()*2 - 1,
+		(screen[1]-vp.Y())/vp.H()*2 - 1,
+		depth*2 - 1,
+	}
+
+	return c.Proj().Mul(c.View()).Inv().Transform(ndc)
+}
+
+// Ray returns the world space origin (on the near plane) and normalized
+// direction of the ray passing through the given window coordinates.
+func (c Cam) Ray(screen Vec) (origin, dir Vec) {
+	origin = c.Unproject(screen, 0)
+	dir = c.Unproject(screen, 1).Sub(origin).Nrm()
+
+	return
+}
+
+// Pick returns the world position under the given window coordinates on the
+// plane at the given z. It reports false if the plane is not in front of the
+// camera.
+func (c Cam) Pick(screen Vec, z float32) (Vec, bool) {
+	origin, dir := c.Ray(screen)
+	if dir[2] == 0 {
+		return Vec{}, false
+	}
+
+	t := (z - origin[2]) / dir[2]
+	if t < 0 {
+		return Vec{}, false
+	}
+
+	p := origin.Add(dir.Scl(t))
+	p[2] = z
+
+	return p, true
+}
@@ -0,0 +1,185 @@
+package blit
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+func TestCamBlockStd140(t *testing.T) {
+	c := NewCam()
+	c.Viewport = Rect{0, 0, 160, 90}
+	c.Vec = Vec{1, 2, 3}
+	c.Pitch(-0.4)
+
+	b := CamBlock{c.View(), c.Proj()}
+
+	want, err := bgl.EncodeStd140(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := b.std140(); !bytes.Equal(got, want) {
+		t.Errorf("std140() = % x, want % x", got, want)
+	}
+}
+
+// testCams returns a perspective and an orthographic camera with offset
+// viewports, positioned and rotated so that the origin is in view
+func testCams() map[string]Cam {
+	persp := NewCam()
+	persp.Viewport = Rect{100, 50, 320, 180}
+	persp.Vec = Vec{1, 2, 8}
+	persp.Yaw(0.1)
+	persp.Pitch(-0.2)
+
+	ortho := NewOrthoCam(1.0 / 16)
+	ortho.Viewport = Rect{20, 10, 161, 91}
+	ortho.Vec = Vec{3, 4, 10}
+	ortho.Zoom = 2
+	ortho.Snap = false
+
+	return map[string]Cam{"perspective": persp, "ortho": ortho}
+}
+
+func vecWithin(v, want Vec, eps float32) bool {
+	for i := 0; i < 3; i++ {
+		if math.Abs(float64(v[i]-want[i])) > float64(eps) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCamProjectUnproject(t *testing.T) {
+	points := []Vec{{0, 0, 0}, {1, -1, 0}, {-2, 3, 1}, {0.5, 0.25, -3}}
+
+	for name, c := range testCams() {
+		for _, p := range points {
+			s := c.Project(p)
+
+			if s[2] < 0 || s[2] > 1 {
+				t.Errorf("%v: Project(%v) depth = %v, want it in [0, 1]", name, p, s[2])
+			}
+
+			if got := c.Unproject(s, s[2]); !vecWithin(got, p, 1e-3) {
+				t.Errorf("%v: Unproject(Project(%v)) = %v", name, p, got)
+			}
+		}
+
+		// window coordinates back to window coordinates, at any depth
+		vp := c.Viewport
+		for _, s := range []Vec{{vp.X(), vp.Y()}, {vp.X() + vp.W()/2, vp.Y() + vp.H()/2}, {vp.X() + 30, vp.Y() + vp.H() - 5}} {
+			for _, depth := range []float32{0, 0.5, 0.9} {
+				if got := c.Project(c.Unproject(s, depth)); !vecWithin(got, Vec{s[0], s[1], depth}, 1e-2) {
+					t.Errorf("%v: Project(Unproject(%v, %v)) = %v", name, s, depth, got)
+				}
+			}
+		}
+
+		// points along the view direction land in the center of the viewport,
+		// on a pixel edge for odd orthographic viewports
+		center := Vec{vp.X() + float32(int(vp.W()/2)), vp.Y() + float32(int(vp.H()/2))}
+		if got := c.Project(c.Pos().Add(c.Forward().Scl(5))); !vecWithin(Vec{got[0], got[1]}, center, 1e-3) {
+			t.Errorf("%v: Project(forward) = %v, want the center %v", name, got, center)
+		}
+	}
+}
+
+func TestCamPickOrtho(t *testing.T) {
+	c := testCams()["ortho"]
+
+	// a pixel covers 1/32 world units at zoom 2, the camera position is on the
+	// pixel 80 pixels right of and 45 pixels above the bottom left corner
+	tests := []struct {
+		screen Vec
+		z      float32
+		want   Vec
+	}{
+		{Vec{100, 55}, 0, Vec{3, 4, 0}},
+		{Vec{100, 55}, 2.5, Vec{3, 4, 2.5}},
+		{Vec{132, 55}, 0, Vec{4, 4, 0}},
+		{Vec{20, 10}, 0, Vec{3 - 80.0/32, 4 - 45.0/32, 0}},
+		{Vec{181, 101}, -1, Vec{3 + 81.0/32, 4 + 46.0/32, -1}},
+	}
+
+	for _, tt := range tests {
+		got, ok := c.Pick(tt.screen, tt.z)
+		if !ok {
+			t.Errorf("Pick(%v, %v) failed", tt.screen, tt.z)
+			continue
+		}
+
+		if !vecWithin(got, tt.want, 1e-4) {
+			t.Errorf("Pick(%v, %v) = %v, want %v", tt.screen, tt.z, got, tt.want)
+		}
+	}
+
+	// the camera is at z 10 looking down
+	if p, ok := c.Pick(Vec{100, 55}, 20); ok {
+		t.Errorf("Pick behind the camera = %v, want no hit", p)
+	}
+}
+
+func TestCamPickPerspective(t *testing.T) {
+	c := NewCam()
+	c.Viewport = Rect{0, 0, 200, 100}
+	c.Vec = Vec{1, -1, 10}
+
+	// the edges of the view at distance d are d*tan(fov/2) from the center
+	half := float32(math.Tan(float64(mgl32.DegToRad(c.FOV)) / 2))
+
+	tests := []struct {
+		screen Vec
+		z      float32
+		want   Vec
+	}{
+		{Vec{100, 50}, 0, Vec{1, -1, 0}},
+		{Vec{100, 50}, 5, Vec{1, -1, 5}},
+		{Vec{100, 100}, 0, Vec{1, -1 + 10*half, 0}},
+		{Vec{200, 50}, 0, Vec{1 + 20*half, -1, 0}},
+		{Vec{0, 0}, 6, Vec{1 - 8*half, -1 - 4*half, 6}},
+	}
+
+	for _, tt := range tests {
+		got, ok := c.Pick(tt.screen, tt.z)
+		if !ok {
+			t.Errorf("Pick(%v, %v) failed", tt.screen, tt.z)
+			continue
+		}
+
+		if !vecWithin(got, tt.want, 1e-3) {
+			t.Errorf("Pick(%v, %v) = %v, want %v", tt.screen, tt.z, got, tt.want)
+		}
+
+		// picked points project back onto the cursor
+		if s := c.Project(got); !vecWithin(Vec{s[0], s[1]}, tt.screen, 1e-2) {
+			t.Errorf("Project(Pick(%v, %v)) = %v", tt.screen, tt.z, s)
+		}
+	}
+
+	if p, ok := c.Pick(Vec{100, 50}, 20); ok {
+		t.Errorf("Pick behind the camera = %v, want no hit", p)
+	}
+
+	// looking along +y, the center ray is parallel to the plane
+	c.Pitch(math.Pi / 2)
+	if p, ok := c.Pick(Vec{100, 50}, 0); ok {
+		t.Errorf("Pick parallel to the plane = %v, want no hit", p)
+	}
+
+	// rays are normalized and start on the near plane
+	c = NewCam()
+	c.Viewport = Rect{0, 0, 200, 100}
+	origin, dir := c.Ray(Vec{150, 20})
+	if l := dir.Len(); math.Abs(float64(l-1)) > 1e-4 {
+		t.Errorf("Ray direction has length %v", l)
+	}
+	if d := c.Pos().Sub(origin).Dot(c.Forward()); math.Abs(float64(d+c.Near)) > 1e-4 {
+		t.Errorf("Ray origin is %v in front of the camera, want the near plane %v", -d, c.Near)
+	}
+}
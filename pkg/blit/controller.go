@@ -0,0 +1,296 @@
+package blit
+
+import (
+	"math"
+	"time"
+
+	"github.com/octalide/wisp/pkg/wisp"
+)
+
+// CamController drives a 2D camera: dragging, zooming towards the cursor,
+// following an Orienter, screen shake and clamping the view to world bounds.
+//
+// Input is given in window coordinates (origin bottom left, see
+// Window.Screen) and time as elapsed durations, so a controller can be driven
+// without a window. Picking needs the view area, which is Cam.Viewport or, if
+// that is empty, Viewport; the controller never queries it from OpenGL.
+// Listen wires it up to the input events of a window.
+type CamController struct {
+	Cam *Cam
+
+	Viewport Rect // view area used when Cam.Viewport is empty, kept at the window size by Listen
+
+	Plane float32 // z of the plane that is dragged and zoomed on
+
+	Target    *Orienter // followed orienter, nil to disable following
+	Offset    Vec       // offset of the camera from the target
+	DeadZone  Vec       // half size of the area the target can move in without being followed
+	Smoothing float32   // follow speed, higher is faster (1/s), zero snaps to the target
+
+	Bounds Rect // world area the view is kept within, ignored if empty
+
+	Shake Shake
+
+	PanButton Key // mouse button used to drag the view in Listen
+
+	dragging bool
+	drag     Vec // window coordinates of the last drag position
+
+	shake     Vec     // shake offset currently applied to the camera
+	shakeRoll float32 // shake rotation currently applied to the camera
+}
+
+// NewCamController creates a controller for the given camera, dragging with
+// the middle mouse button
+func NewCamController(cam *Cam) *CamController {
+	return &CamController{
+		Cam:       cam,
+		Shake:     NewShake(),
+		PanButton: MouseButtonMiddle,
+	}
+}
+
+// withViewport runs fn with the camera viewport set to the view area. It
+// reports false without running fn if the view area is unknown.
+func (cc *CamController) withViewport(fn func()) bool {
+	if cc.Cam.Viewport.W() > 0 && cc.Cam.Viewport.H() > 0 {
+		fn()
+		return true
+	}
+
+	if cc.Viewport.W() <= 0 || cc.Viewport.H() <= 0 {
+		return false
+	}
+
+	cc.Cam.Viewport = cc.Viewport
+	fn()
+	cc.Cam.Viewport = Rect{}
+
+	return true
+}
+
+// DragStart starts dragging the world at the given window coordinates
+func (cc *CamController) DragStart(screen Vec) {
+	cc.dragging = true
+	cc.drag = screen
+}
+
+// Drag moves the camera so that the world position under the last drag
+// position ends up under the given window coordinates
+func (cc *CamController) Drag(screen Vec) {
+	if !cc.dragging {
+		return
+	}
+
+	cc.withViewport(func() {
+		from, ok := cc.Cam.Pick(cc.drag, cc.Plane)
+		to, ok2 := cc.Cam.Pick(screen, cc.Plane)
+		if ok && ok2 {
+			cc.Cam.Pan(from.Sub(to))
+		}
+	})
+
+	cc.drag = screen
+}
+
+// DragEnd stops dragging
+func (cc *CamController) DragEnd() {
+	cc.dragging = false
+}
+
+// Dragging reports whether the world is being dragged
+func (cc *CamController) Dragging() bool {
+	return cc.dragging
+}
+
+// ZoomAt zooms by the given number of steps towards the given window
+// coordinates
+func (cc *CamController) ZoomAt(screen Vec, steps int) {
+	cc.withViewport(func() {
+		cc.Cam.ZoomAt(screen, steps, cc.Plane)
+	})
+}
+
+// Update advances following, shaking and bounds clamping by dt
+func (cc *CamController) Update(dt time.Duration) {
+	// work on the camera position without shake
+	cc.Cam.Vec = cc.Cam.Vec.Sub(cc.shake)
+	cc.Cam.R -= cc.shakeRoll
+
+	if cc.Target != nil {
+		cc.follow(float32(dt.Seconds()))
+	}
+
+	if cc.Bounds.W() > 0 && cc.Bounds.H() > 0 {
+		cc.withViewport(cc.clamp)
+	}
+
+	cc.Shake.Update(dt)
+	cc.shake, cc.shakeRoll = cc.Shake.Offset()
+
+	cc.Cam.Vec = cc.Cam.Vec.Add(cc.shake)
+	cc.Cam.R += cc.shakeRoll
+}
+
+// follow moves the camera towards the target, ignoring movement inside the
+// dead zone
+func (cc *CamController) follow(dt float32) {
+	target := cc.Target.Pos().Add(cc.Offset)
+	pos := cc.Cam.Vec
+	goal := pos
+
+	for i := 0; i < 2; i++ {
+		d := target[i] - pos[i]
+		if d > cc.DeadZone[i] {
+			goal[i] = target[i] - cc.DeadZone[i]
+		} else if d < -cc.DeadZone[i] {
+			goal[i] = target[i] + cc.DeadZone[i]
+		}
+	}
+
+	if cc.Smoothing <= 0 {
+		cc.Cam.Vec = goal
+		return
+	}
+
+	// frame rate independent exponential smoothing
+	t := 1 - float32(math.Exp(float64(-cc.Smoothing*dt)))
+	cc.Cam.Vec = pos.Add(goal.Sub(pos).Scl(t))
+}
+
+// clamp keeps the view inside the bounds, centering it on any axis where the
+// view is larger than the bounds. The camera viewport must be set.
+func (cc *CamController) clamp() {
+	vp := cc.Cam.Viewport
+
+	min, ok := cc.Cam.Pick(Vec{vp.X(), vp.Y()}, cc.Plane)
+	max, ok2 := cc.Cam.Pick(Vec{vp.X() + vp.W(), vp.Y() + vp.H()}, cc.Plane)
+	if !ok || !ok2 {
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		half := (max[i] - min[i]) / 2
+		center := (max[i] + min[i]) / 2
+
+		lo := cc.Bounds[i]
+		hi := cc.Bounds[i] + cc.Bounds[i+2]
+
+		var shift float32
+		switch {
+		case hi-lo < half*2:
+			shift = (lo+hi)/2 - center
+		case center-half < lo:
+			shift = lo - (center - half)
+		case center+half > hi:
+			shift = hi - (center + half)
+		}
+
+		cc.Cam.Vec[i] += shift
+	}
+}
+
+// Listen adds a wisp handler driving the controller from the input events of
+// the given window: dragging with PanButton and zooming with the scroll wheel.
+// Viewport follows the window size.
+func (cc *CamController) Listen(win *Window) *wisp.Handler {
+	cc.Viewport = Rect{0, 0, float32(win.GetWidth()), float32(win.GetHeight())}
+
+	h := &wisp.Handler{
+		Callback: func(e *wisp.Event) bool {
+			switch e.Tag {
+			case "core.window.resize":
+				size := e.Data.(Vec)
+				cc.Viewport = Rect{0, 0, size.X(), size.Y()}
+			case "core.input.mouse.scroll":
+				delta := e.Data.(Vec).Y()
+				if delta > 0 {
+					cc.ZoomAt(win.Screen(MousePos()), 1)
+				} else if delta < 0 {
+					cc.ZoomAt(win.Screen(MousePos()), -1)
+				}
+			case "core.input.mouse.move":
+				cc.Drag(win.Screen(e.Data.(Vec)))
+			case "core.input.mouse.button.down":
+				if e.Data.(Key) == cc.PanButton {
+					cc.DragStart(win.Screen(MousePos()))
+				}
+			case "core.input.mouse.button.up":
+				if e.Data.(Key) == cc.PanButton {
+					cc.DragEnd()
+				}
+			}
+
+			return false
+		},
+		Tags:     []string{"core.input.mouse", "core.window.resize"},
+		Blocking: false,
+	}
+
+	wisp.AddHandler(h)
+
+	return h
+}
+
+// Shake is a trauma based screen shake. Trauma is added by events (hits,
+// explosions) and decays over time; the strength of the shake is the square
+// of the trauma.
+type Shake struct {
+	Trauma    float32 // current trauma in [0, 1]
+	Decay     float32 // trauma removed per second
+	MaxOffset Vec     // offset at full trauma (world units)
+	MaxRoll   float32 // rotation at full trauma (radians)
+	Frequency float32 // shake speed (Hz)
+
+	t float64
+}
+
+// NewShake creates a shake decaying from full trauma in one second
+func NewShake() Shake {
+	return Shake{
+		Decay:     1,
+		MaxOffset: Vec{0.5, 0.5},
+		MaxRoll:   0.05,
+		Frequency: 15,
+	}
+}
+
+// Add adds trauma, clamped to 1
+func (s *Shake) Add(trauma float32) {
+	s.Trauma += trauma
+	if s.Trauma > 1 {
+		s.Trauma = 1
+	}
+}
+
+// Update advances the shake by dt and decays the trauma
+func (s *Shake) Update(dt time.Duration) {
+	s.t += dt.Seconds()
+
+	s.Trauma -= s.Decay * float32(dt.Seconds())
+	if s.Trauma < 0 {
+		s.Trauma = 0
+	}
+}
+
+// Offset returns the current shake offset and rotation
+func (s *Shake) Offset() (Vec, float32) {
+	if s.Trauma <= 0 {
+		return Vec{}, 0
+	}
+
+	k := s.Trauma * s.Trauma
+	t := s.t * float64(s.Frequency)
+
+	return Vec{
+		s.MaxOffset[0] * k * shakeNoise(t, 0),
+		s.MaxOffset[1] * k * shakeNoise(t, 1),
+	}, s.MaxRoll * k * shakeNoise(t, 2)
+}
+
+// shakeNoise returns smooth deterministic noise in [-1, 1] for a channel
+func shakeNoise(t float64, channel int) float32 {
+	p := float64(channel) * 1.7
+
+	return float32(0.5*math.Sin(t+p) + 0.3*math.Sin(2.3*t+3.1*p) + 0.2*math.Sin(5.7*t+5.3*p))
+}
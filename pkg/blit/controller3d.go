@@ -0,0 +1,339 @@
+package blit
+
+import (
+	"math"
+	"time"
+
+	"github.com/octalide/wisp/pkg/wisp"
+)
+
+// defaultMaxPitch keeps the camera just short of looking straight up or down
+const defaultMaxPitch = 89 * math.Pi / 180
+
+// camAngles returns the yaw and pitch of the camera's forward direction
+func camAngles(c *Cam) (yaw, pitch float32) {
+	f := c.Forward()
+
+	yaw = float32(math.Atan2(float64(-f[0]), float64(-f[2])))
+	pitch = float32(math.Asin(math.Max(-1, math.Min(1, float64(f[1])))))
+
+	return
+}
+
+// clampPitch limits pitch to [-max, max]
+func clampPitch(pitch, max float32) float32 {
+	if pitch > max {
+		return max
+	}
+	if pitch < -max {
+		return -max
+	}
+
+	return pitch
+}
+
+// FlyController is a first person free-fly camera controller: keys move the
+// camera along its view direction and mouse movement turns it. Roll is always
+// zero and pitch is clamped to MaxPitch.
+type FlyController struct {
+	Cam *Cam
+
+	Enabled     bool
+	Speed       float32 // movement speed (units per second)
+	Boost       float32 // speed multiplier while BoostKey is held
+	Sensitivity float32 // rotation per pixel of mouse movement (radians)
+	MaxPitch    float32 // maximum pitch up or down (radians)
+
+	Forward, Back, Left, Right, Up, Down, BoostKey Key
+
+	Yaw, Pitch float32 // current orientation (radians)
+
+	captured bool
+	skip     bool // ignore the jump in cursor position after capturing
+}
+
+// NewFlyController creates an enabled fly controller using WASD to move,
+// space and left control to rise and sink and left shift to move faster. The
+// orientation is taken from the camera.
+func NewFlyController(cam *Cam) *FlyController {
+	f := &FlyController{
+		Cam:         cam,
+		Enabled:     true,
+		Speed:       5,
+		Boost:       4,
+		Sensitivity: 0.0025,
+		MaxPitch:    defaultMaxPitch,
+		Forward:     KeyW,
+		Back:        KeyS,
+		Left:        KeyA,
+		Right:       KeyD,
+		Up:          KeySpace,
+		Down:        KeyLeftControl,
+		BoostKey:    KeyLeftShift,
+	}
+
+	f.Sync()
+
+	return f
+}
+
+// Sync takes the yaw and pitch from the current camera orientation
+func (f *FlyController) Sync() {
+	f.Yaw, f.Pitch = camAngles(f.Cam)
+	f.Pitch = clampPitch(f.Pitch, f.MaxPitch)
+}
+
+// Look turns the camera by a mouse movement in pixels (cursor coordinates,
+// y pointing down)
+func (f *FlyController) Look(delta Vec) {
+	f.Yaw -= delta[0] * f.Sensitivity
+	f.Pitch = clampPitch(f.Pitch-delta[1]*f.Sensitivity, f.MaxPitch)
+
+	f.apply()
+}
+
+// Move moves the camera for dt in a direction relative to the view: x right,
+// y up (in world space) and z backwards. The direction is normalized.
+func (f *FlyController) Move(dir Vec, dt time.Duration) {
+	dir[3] = 0
+	if dir.Len() == 0 {
+		return
+	}
+	dir = dir.Nrm()
+
+	v := f.Cam.Right().Scl(dir[0]).
+		Add(Vec{0, dir[1], 0}).
+		Add(f.Cam.Forward().Scl(-dir[2]))
+
+	f.Cam.Pan(v.Scl(f.Speed * float32(dt.Seconds())))
+}
+
+// Update moves the camera by the held movement keys for dt
+func (f *FlyController) Update(dt time.Duration) {
+	if !f.Enabled {
+		return
+	}
+
+	var dir Vec
+	if Keys(f.Forward) {
+		dir[2]--
+	}
+	if Keys(f.Back) {
+		dir[2]++
+	}
+	if Keys(f.Left) {
+		dir[0]--
+	}
+	if Keys(f.Right) {
+		dir[0]++
+	}
+	if Keys(f.Up) {
+		dir[1]++
+	}
+	if Keys(f.Down) {
+		dir[1]--
+	}
+
+	if Keys(f.BoostKey) {
+		dt = time.Duration(float32(dt) * f.Boost)
+	}
+
+	f.Move(dir, dt)
+}
+
+// Capture hides and captures the cursor so that mouse movement turns the
+// camera
+func (f *FlyController) Capture(win *Window) {
+	win.HideCursor()
+	f.captured = true
+	f.skip = true
+}
+
+// Release shows the cursor again
+func (f *FlyController) Release(win *Window) {
+	win.ShowCursor()
+	f.captured = false
+}
+
+// Captured reports whether the cursor is captured
+func (f *FlyController) Captured() bool {
+	return f.captured
+}
+
+// Listen adds a wisp handler turning the camera with the mouse while the
+// cursor is captured. Clicking into the window captures the cursor and escape
+// releases it.
+func (f *FlyController) Listen(win *Window) *wisp.Handler {
+	h := &wisp.Handler{
+		Callback: func(e *wisp.Event) bool {
+			if !f.Enabled {
+				return false
+			}
+
+			switch e.Tag {
+			case "core.input.mouse.move":
+				if !f.captured {
+					break
+				}
+				if f.skip {
+					f.skip = false
+					break
+				}
+
+				f.Look(MouseDelta())
+			case "core.input.mouse.button.down":
+				if !f.captured {
+					f.Capture(win)
+				}
+			case "core.input.key.down":
+				if e.Data.(Key) == KeyEscape && f.captured {
+					f.Release(win)
+				}
+			}
+
+			return false
+		},
+		Tags:     []string{"core.input"},
+		Blocking: false,
+	}
+
+	wisp.AddHandler(h)
+
+	return h
+}
+
+// apply sets the camera orientation from yaw and pitch
+func (f *FlyController) apply() {
+	f.Cam.Q = QuatEuler(f.Pitch, f.Yaw, 0)
+	f.Cam.R = 0
+}
+
+// OrbitController rotates the camera around a target point at a distance.
+// Dragging with RotateButton orbits, dragging with PanButton moves the target
+// and scrolling changes the distance.
+type OrbitController struct {
+	Cam *Cam
+
+	Enabled bool
+	Target  Vec // point orbited around
+
+	Distance    float32 // distance from the target
+	MinDistance float32 // minimum distance from the target
+	MaxDistance float32 // maximum distance from the target, zero for no limit
+
+	Sensitivity float32 // rotation per pixel of mouse movement (radians)
+	PanSpeed    float32 // target movement per pixel as a fraction of the distance
+	ZoomSpeed   float32 // fraction of the distance removed per scroll step
+	MaxPitch    float32 // maximum pitch up or down (radians)
+
+	RotateButton, PanButton Key
+
+	Yaw, Pitch float32 // current orientation (radians)
+}
+
+// NewOrbitController creates an enabled orbit controller orbiting the point
+// the camera looks at, at the given distance
+func NewOrbitController(cam *Cam, distance float32) *OrbitController {
+	o := &OrbitController{
+		Cam:          cam,
+		Enabled:      true,
+		Distance:     distance,
+		MinDistance:  0.1,
+		Sensitivity:  0.005,
+		PanSpeed:     0.002,
+		ZoomSpeed:    0.1,
+		MaxPitch:     defaultMaxPitch,
+		RotateButton: MouseButtonLeft,
+		PanButton:    MouseButtonMiddle,
+	}
+
+	o.Sync()
+
+	return o
+}
+
+// Sync takes the yaw and pitch from the current camera orientation and moves
+// the target to the point Distance in front of the camera
+func (o *OrbitController) Sync() {
+	o.Yaw, o.Pitch = camAngles(o.Cam)
+	o.Pitch = clampPitch(o.Pitch, o.MaxPitch)
+	o.Target = o.Cam.Pos().Add(o.Cam.Forward().Scl(o.Distance))
+}
+
+// Rotate orbits by a mouse movement in pixels (cursor coordinates, y pointing
+// down)
+func (o *OrbitController) Rotate(delta Vec) {
+	o.Yaw -= delta[0] * o.Sensitivity
+	o.Pitch = clampPitch(o.Pitch-delta[1]*o.Sensitivity, o.MaxPitch)
+
+	o.Update()
+}
+
+// Pan moves the target in the view plane by a mouse movement in pixels
+// (cursor coordinates, y pointing down)
+func (o *OrbitController) Pan(delta Vec) {
+	k := o.Distance * o.PanSpeed
+	v := o.Cam.Right().Scl(-delta[0] * k).Add(o.Cam.Up().Scl(delta[1] * k))
+
+	o.Target = o.Target.Add(v)
+
+	o.Update()
+}
+
+// Zoom moves towards (positive steps) or away from the target
+func (o *OrbitController) Zoom(steps int) {
+	o.Distance *= float32(math.Pow(float64(1-o.ZoomSpeed), float64(steps)))
+
+	o.Update()
+}
+
+// Update clamps the distance and places the camera on the orbit
+func (o *OrbitController) Update() {
+	if o.MaxDistance > 0 && o.Distance > o.MaxDistance {
+		o.Distance = o.MaxDistance
+	}
+	if o.Distance < o.MinDistance {
+		o.Distance = o.MinDistance
+	}
+
+	q := QuatEuler(o.Pitch, o.Yaw, 0)
+
+	o.Cam.Q = q
+	o.Cam.R = 0
+	o.Cam.Vec = o.Target.Add(q.Rotate(Vec{0, 0, o.Distance}))
+}
+
+// Listen adds a wisp handler driving the controller from the mouse
+func (o *OrbitController) Listen(win *Window) *wisp.Handler {
+	h := &wisp.Handler{
+		Callback: func(e *wisp.Event) bool {
+			if !o.Enabled {
+				return false
+			}
+
+			switch e.Tag {
+			case "core.input.mouse.move":
+				if Keys(o.RotateButton) {
+					o.Rotate(MouseDelta())
+				} else if Keys(o.PanButton) {
+					o.Pan(MouseDelta())
+				}
+			case "core.input.mouse.scroll":
+				delta := e.Data.(Vec).Y()
+				if delta > 0 {
+					o.Zoom(1)
+				} else if delta < 0 {
+					o.Zoom(-1)
+				}
+			}
+
+			return false
+		},
+		Tags:     []string{"core.input.mouse"},
+		Blocking: false,
+	}
+
+	wisp.AddHandler(h)
+
+	return h
+}
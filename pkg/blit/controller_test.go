@@ -0,0 +1,243 @@
+package blit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testCam returns an orthographic camera showing 10x10 world units at a zoom
+// of 1 through an explicit 160x160 viewport
+func testCam() *Cam {
+	cam := NewOrthoCam(1.0 / 16)
+	cam.Snap = false
+	cam.Viewport = Rect{0, 0, 160, 160}
+
+	return &cam
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestCamControllerDeadZone(t *testing.T) {
+	tests := []struct {
+		name   string
+		target Vec
+		want   Vec
+	}{
+		{"inside", Vec{0.5, -0.8}, Vec{0, 0}},
+		{"edge", Vec{1, 1}, Vec{0, 0}},
+		{"right", Vec{3, 0}, Vec{2, 0}},
+		{"below left", Vec{-4, -1.5}, Vec{-3, -0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := NewCamController(testCam())
+			cc.Cam.Vec = Vec{0, 0, 1}
+			cc.Target = &Orienter{Vec: tt.target}
+			cc.DeadZone = Vec{1, 1}
+
+			cc.Update(16 * time.Millisecond)
+
+			if got := cc.Cam.Vec; !near(got.X(), tt.want.X()) || !near(got.Y(), tt.want.Y()) {
+				t.Errorf("camera at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCamControllerSmoothing(t *testing.T) {
+	follow := func(steps int, dt time.Duration) Vec {
+		cc := NewCamController(testCam())
+		cc.Cam.Vec = Vec{0, 0, 1}
+		cc.Target = &Orienter{Vec: Vec{10, -5}}
+		cc.Smoothing = 4
+
+		last := cc.Cam.Vec.X()
+		for i := 0; i < steps; i++ {
+			cc.Update(dt)
+
+			x := cc.Cam.Vec.X()
+			if x < last || x > 10 {
+				t.Fatalf("step %v: camera at %v after %v, want monotonic approach to 10", i, x, last)
+			}
+			last = x
+		}
+
+		return cc.Cam.Vec
+	}
+
+	// half a second at different frame rates ends up in the same place
+	a := follow(5, 100*time.Millisecond)
+	b := follow(50, 10*time.Millisecond)
+	if !near(a.X(), b.X()) || !near(a.Y(), b.Y()) {
+		t.Errorf("frame rate dependent: %v at 10 fps, %v at 100 fps", a, b)
+	}
+
+	want := 10 * (1 - float32(math.Exp(-4*0.5)))
+	if !near(a.X(), want) {
+		t.Errorf("camera at %v after 0.5s, want %v", a.X(), want)
+	}
+
+	// converges on the target
+	if got := follow(300, 16*time.Millisecond); !near(got.X(), 10) || !near(got.Y(), -5) {
+		t.Errorf("camera at %v after 4.8s, want [10 -5]", got)
+	}
+}
+
+func TestCamControllerBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds Rect
+		pos    Vec
+		zoom   int
+		want   Vec
+	}{
+		{"inside", Rect{-20, -20, 40, 40}, Vec{3, -4}, 1, Vec{3, -4}},
+		{"right", Rect{-20, -20, 40, 40}, Vec{100, 0}, 1, Vec{15, 0}},
+		{"bottom left", Rect{-20, -20, 40, 40}, Vec{-18, -30}, 1, Vec{-15, -15}},
+		{"zoomed in", Rect{-20, -20, 40, 40}, Vec{100, 100}, 2, Vec{17.5, 17.5}},
+		{"larger than bounds", Rect{0, 0, 6, 4}, Vec{50, -50}, 1, Vec{3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := NewCamController(testCam())
+			cc.Cam.SetZoom(tt.zoom)
+			cc.Cam.Vec = Vec{tt.pos.X(), tt.pos.Y(), 1}
+			cc.Bounds = tt.bounds
+
+			cc.Update(16 * time.Millisecond)
+
+			if got := cc.Cam.Vec; !near(got.X(), tt.want.X()) || !near(got.Y(), tt.want.Y()) {
+				t.Errorf("camera at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCamControllerViewport(t *testing.T) {
+	// the controller viewport is used when the camera has none, and left unset
+	cam := testCam()
+	cam.Viewport = Rect{}
+	cam.Vec = Vec{100, 0, 1}
+
+	cc := NewCamController(cam)
+	cc.Viewport = Rect{0, 0, 160, 160}
+	cc.Bounds = Rect{-20, -20, 40, 40}
+
+	cc.Update(16 * time.Millisecond)
+
+	if got := cam.Vec.X(); !near(got, 15) {
+		t.Errorf("camera at %v, want 15", got)
+	}
+	if cam.Viewport != (Rect{}) {
+		t.Errorf("camera viewport left at %v", cam.Viewport)
+	}
+}
+
+func TestCamControllerZoom(t *testing.T) {
+	tests := []struct {
+		name  string
+		max   int
+		steps []int
+		want  int
+	}{
+		{"in", 8, []int{1, 1}, 3},
+		{"out below 1", 8, []int{-1, -1, -5}, 1},
+		{"max", 4, []int{2, 2, 2}, 4},
+		{"unlimited", 0, []int{20}, 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := NewCamController(testCam())
+			cc.Cam.MaxZoom = tt.max
+
+			for _, s := range tt.steps {
+				cc.ZoomAt(Vec{80, 80}, s)
+			}
+
+			if cc.Cam.Zoom != tt.want {
+				t.Errorf("zoom %v, want %v", cc.Cam.Zoom, tt.want)
+			}
+		})
+	}
+
+	// zooming towards a point keeps it under the cursor
+	cc := NewCamController(testCam())
+	screen := Vec{120, 40}
+	before, _ := cc.Cam.Pick(screen, 0)
+	cc.ZoomAt(screen, 3)
+	after, _ := cc.Cam.Pick(screen, 0)
+
+	if !near(before.X(), after.X()) || !near(before.Y(), after.Y()) {
+		t.Errorf("world position under the cursor moved from %v to %v", before, after)
+	}
+}
+
+func TestShakeDecay(t *testing.T) {
+	s := NewShake()
+	s.Add(0.5)
+	s.Add(0.8)
+
+	if s.Trauma != 1 {
+		t.Fatalf("trauma %v, want it clamped to 1", s.Trauma)
+	}
+
+	steps := []struct {
+		dt   time.Duration
+		want float32
+	}{
+		{250 * time.Millisecond, 0.75},
+		{250 * time.Millisecond, 0.5},
+		{100 * time.Millisecond, 0.4},
+		{time.Second, 0},
+	}
+
+	for _, st := range steps {
+		s.Update(st.dt)
+
+		if !near(s.Trauma, st.want) {
+			t.Errorf("trauma %v, want %v", s.Trauma, st.want)
+		}
+
+		// the shake strength is the square of the trauma
+		off, roll := s.Offset()
+		k := st.want * st.want
+		if math.Abs(float64(off.X())) > float64(s.MaxOffset.X()*k)+1e-6 ||
+			math.Abs(float64(off.Y())) > float64(s.MaxOffset.Y()*k)+1e-6 ||
+			math.Abs(float64(roll)) > float64(s.MaxRoll*k)+1e-6 {
+			t.Errorf("offset %v, %v exceeds the maximum at trauma %v", off, roll, st.want)
+		}
+	}
+
+	if off, roll := s.Offset(); off != (Vec{}) || roll != 0 {
+		t.Errorf("offset %v, %v without trauma, want none", off, roll)
+	}
+}
+
+func TestCamControllerShake(t *testing.T) {
+	// shake is applied on top of the camera position and fully removed once
+	// the trauma has decayed
+	cc := NewCamController(testCam())
+	cc.Cam.Vec = Vec{2, 3, 1}
+	cc.Shake.Add(1)
+
+	moved := false
+	for i := 0; i < 100; i++ {
+		cc.Update(16 * time.Millisecond)
+		if cc.Cam.Vec.X() != 2 || cc.Cam.Vec.Y() != 3 {
+			moved = true
+		}
+	}
+
+	if !moved {
+		t.Error("camera never shook")
+	}
+	if got := cc.Cam.Vec; !near(got.X(), 2) || !near(got.Y(), 3) || !near(cc.Cam.R, 0) {
+		t.Errorf("camera at %v, rotated %v after the shake, want [2 3] and 0", got, cc.Cam.R)
+	}
+}
@@ -0,0 +1,92 @@
+package blit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a Diagnostic
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // suspicious but usable
+	SeverityError                   // invalid, loading fails
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+
+	return "warning"
+}
+
+// Diagnostic is a problem found while validating an asset description
+type Diagnostic struct {
+	Severity
+	Name    string // name of the sprite (or other entry) the problem is about
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Name == "" {
+		return fmt.Sprintf("%v: %v", d.Severity, d.Message)
+	}
+
+	return fmt.Sprintf("%v: \"%v\": %v", d.Severity, d.Name, d.Message)
+}
+
+// Diagnostics is a list of problems. It implements error so that all problems
+// can be returned at once; see Err.
+type Diagnostics []Diagnostic
+
+// errorf adds an error
+func (d *Diagnostics) errorf(name, format string, args ...interface{}) {
+	*d = append(*d, Diagnostic{SeverityError, name, fmt.Sprintf(format, args...)})
+}
+
+// warnf adds a warning
+func (d *Diagnostics) warnf(name, format string, args ...interface{}) {
+	*d = append(*d, Diagnostic{SeverityWarning, name, fmt.Sprintf(format, args...)})
+}
+
+// Errors returns the diagnostics with error severity
+func (d Diagnostics) Errors() Diagnostics {
+	return d.filter(SeverityError)
+}
+
+// Warnings returns the diagnostics with warning severity
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(SeverityWarning)
+}
+
+func (d Diagnostics) filter(s Severity) Diagnostics {
+	var out Diagnostics
+	for _, diag := range d {
+		if diag.Severity == s {
+			out = append(out, diag)
+		}
+	}
+
+	return out
+}
+
+// Err returns the errors as an error, or nil if there are none
+func (d Diagnostics) Err() error {
+	errs := d.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// Error lists all diagnostics, one per line
+func (d Diagnostics) Error() string {
+	lines := make([]string, len(d))
+	for i, diag := range d {
+		lines[i] = diag.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,160 @@
+package blit
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+// NewGrayscale creates an effect desaturating the image. Uniforms: "amount"
+// (0 to 1, default 1).
+func NewGrayscale() (*Effect, error) {
+	p, err := postProgram("grayscale.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewEffect("grayscale", NewPass(p))
+	e.Set("amount", float32(1))
+
+	return e, nil
+}
+
+// NewVignette creates an effect darkening the corners of the image.
+// Uniforms: "radius" where darkening starts (0 is the center, 1 the corners),
+// "softness" of the falloff and "strength" at the corners.
+func NewVignette() (*Effect, error) {
+	p, err := postProgram("vignette.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewEffect("vignette", NewPass(p))
+	e.Set("radius", float32(0.5))
+	e.Set("softness", float32(0.5))
+	e.Set("strength", float32(0.6))
+
+	return e, nil
+}
+
+// blurPasses returns a horizontal and a vertical gaussian blur pass
+func blurPasses(radius float32) ([]*Pass, error) {
+	p, err := postProgram("blur.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	h, v := NewPass(p), NewPass(p)
+	h.Uniforms["dir"] = [2]float32{1, 0}
+	v.Uniforms["dir"] = [2]float32{0, 1}
+	h.Uniforms["radius"] = radius
+	v.Uniforms["radius"] = radius
+
+	return []*Pass{h, v}, nil
+}
+
+// NewBlur creates a separable gaussian blur. Uniforms: "radius", the spacing
+// of the 9 taps in texels.
+func NewBlur(radius float32) (*Effect, error) {
+	passes, err := blurPasses(radius)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEffect("blur", passes...), nil
+}
+
+// NewBloom creates an effect making bright parts of the image glow. The parts
+// brighter than "threshold" are blurred and added back scaled by "intensity".
+func NewBloom(threshold, intensity float32) (*Effect, error) {
+	bright, err := postProgram("bright.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	blur, err := blurPasses(2)
+	if err != nil {
+		return nil, err
+	}
+
+	combine, err := postProgram("bloom.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewEffect("bloom", NewPass(bright), blur[0], blur[1], NewPass(combine))
+	e.Set("threshold", threshold)
+	e.Set("intensity", intensity)
+
+	return e, nil
+}
+
+// NewCRT creates an effect imitating a CRT screen. Uniforms: "curvature" of
+// the screen, "scanlines" darkness and "flicker" of the brightness.
+func NewCRT() (*Effect, error) {
+	p, err := postProgram("crt.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewEffect("crt", NewPass(p))
+	e.Set("curvature", float32(0.05))
+	e.Set("scanlines", float32(0.25))
+	e.Set("flicker", float32(0.02))
+
+	return e, nil
+}
+
+// NewColorGrade creates an effect mapping colors through a lookup table of
+// size entries per channel, laid out as by NeutralLUT (e.g. a copy of it
+// edited in an image editor). Uniforms: "amount" (0 to 1, default 1).
+func NewColorGrade(lut *bgl.Texture, size int) (*Effect, error) {
+	if size < 2 {
+		return nil, fmt.Errorf("invalid color lookup table size: %v", size)
+	}
+
+	p, err := postProgram("grade.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	pass := NewPass(p)
+	pass.Textures["lut"] = lut
+
+	e := NewEffect("grade", pass)
+	e.Set("size", float32(size))
+	e.Set("amount", float32(1))
+
+	return e, nil
+}
+
+// NeutralLUT creates a color lookup table that maps every color to itself:
+// size slices of size by size pixels next to each other, red increasing to
+// the right, green downwards and blue from slice to slice. Load graded copies
+// with linear filtering. Sizes below 2 are raised to 2, the smallest table
+// holding both ends of each channel.
+func NeutralLUT(size int) *image.RGBA {
+	if size < 2 {
+		size = 2
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size*size, size))
+
+	scale := 255 / float32(size-1)
+	for b := 0; b < size; b++ {
+		for g := 0; g < size; g++ {
+			for r := 0; r < size; r++ {
+				img.SetRGBA(b*size+r, g, color.RGBA{
+					uint8(float32(r)*scale + 0.5),
+					uint8(float32(g)*scale + 0.5),
+					uint8(float32(b)*scale + 0.5),
+					255,
+				})
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,54 @@
+package blit
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestNeutralLUT(t *testing.T) {
+	tests := []struct {
+		size, want int
+	}{
+		{-1, 2},
+		{0, 2},
+		{1, 2},
+		{2, 2},
+		{16, 16},
+		{33, 33},
+	}
+
+	for _, tt := range tests {
+		img := NeutralLUT(tt.size)
+		n := tt.want
+
+		if b := img.Bounds(); b.Dx() != n*n || b.Dy() != n {
+			t.Errorf("NeutralLUT(%v) is %vx%v, want %vx%v", tt.size, b.Dx(), b.Dy(), n*n, n)
+			continue
+		}
+
+		// the corners of the cube map to black, the primaries and white
+		corners := []struct {
+			x, y int
+			want color.RGBA
+		}{
+			{0, 0, color.RGBA{0, 0, 0, 255}},
+			{n - 1, 0, color.RGBA{255, 0, 0, 255}},
+			{0, n - 1, color.RGBA{0, 255, 0, 255}},
+			{(n - 1) * n, 0, color.RGBA{0, 0, 255, 255}},
+			{n*n - 1, n - 1, color.RGBA{255, 255, 255, 255}},
+		}
+
+		for _, c := range corners {
+			if got := img.RGBAAt(c.x, c.y); got != c.want {
+				t.Errorf("NeutralLUT(%v) at (%v, %v) = %v, want %v", tt.size, c.x, c.y, got, c.want)
+			}
+		}
+
+		// every channel increases monotonically
+		for x := 1; x < n; x++ {
+			if a, b := img.RGBAAt(x-1, 0), img.RGBAAt(x, 0); b.R <= a.R {
+				t.Errorf("NeutralLUT(%v) red does not increase at %v: %v, %v", tt.size, x, a.R, b.R)
+			}
+		}
+	}
+}
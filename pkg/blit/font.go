@@ -0,0 +1,309 @@
+package blit
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"io/fs"
+	"math"
+	"strings"
+
+	"github.com/octalide/blit/pkg/atlas"
+	"github.com/octalide/blit/pkg/bgl"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// fontPageSize is the maximum size of the glyph texture of a Font
+const fontPageSize = 4096
+
+// Glyph is the placement of a rasterized glyph relative to the pen position
+type Glyph struct {
+	Offset  Vec     // top left corner of the glyph sprite relative to the pen on the baseline
+	Advance float32 // horizontal distance to the next pen position
+}
+
+// Font is a TrueType or OpenType font rasterized at a fixed size. Every glyph
+// is a sprite of the embedded spritesheet named by its rune as a string;
+// blank glyphs such as spaces only have metrics. Distances are in pixels with
+// the y axis pointing down.
+type Font struct {
+	*Spritesheet
+
+	Size       float32 // pixels per em
+	Ascent     float32 // distance from the top of a line to the baseline
+	Descent    float32 // distance from the baseline to the bottom of a line
+	LineHeight float32 // distance between the baselines of two lines
+	Glyphs     map[rune]Glyph
+
+	kerning map[[2]rune]float32
+}
+
+// defaultFontRunes returns the runes rasterized if none are given, printable
+// ASCII and Latin-1
+func defaultFontRunes() string {
+	var b strings.Builder
+	for r := rune(0x20); r <= 0xff; r++ {
+		if r < 0x7f || r >= 0xa0 {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// GenFont rasterizes the given runes of a TrueType or OpenType font at size
+// pixels per em and uploads the glyphs into a texture. Printable ASCII and
+// Latin-1 are rasterized if runes is empty; runes missing from the font are
+// skipped.
+func GenFont(data []byte, size float32, runes string) (*Font, error) {
+	f, img, err := genFont(data, size, runes)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Texture = bgl.NewTexture(img, f.Filter)
+
+	return f, nil
+}
+
+// LoadFontFS loads a font file from fsys (see GenFont)
+func LoadFontFS(fsys fs.FS, name string, size float32, runes string) (*Font, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := GenFont(data, size, runes)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+
+	return f, nil
+}
+
+// LoadFont loads a font file (see GenFont)
+func LoadFont(path string, size float32, runes string) (*Font, error) {
+	return LoadFontFS(osFS{}, path, size, runes)
+}
+
+// genFont rasterizes a font into a glyph image without creating a texture
+func genFont(data []byte, size float32, runes string) (*Font, *image.RGBA, error) {
+	if size <= 0 {
+		return nil, nil, fmt.Errorf("invalid font size: %v", size)
+	}
+	if runes == "" {
+		runes = defaultFontRunes()
+	}
+
+	sf, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid font: %v", err)
+	}
+
+	var buf sfnt.Buffer
+	ppem := fixed.Int26_6(math.Round(float64(size) * 64))
+
+	m, err := sf.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid font metrics: %v", err)
+	}
+
+	f := &Font{
+		Spritesheet: NewSpritesheet(),
+		Size:        size,
+		Ascent:      fixedFloat(m.Ascent),
+		Descent:     fixedFloat(m.Descent),
+		LineHeight:  fixedFloat(m.Height),
+		Glyphs:      map[rune]Glyph{},
+		kerning:     map[[2]rune]float32{},
+	}
+
+	a := atlas.New(atlas.Options{MaxW: fontPageSize, MaxH: fontPageSize, Padding: 1})
+
+	var found []rune
+	index := map[rune]sfnt.GlyphIndex{}
+	for _, r := range runes {
+		if _, ok := index[r]; ok {
+			continue
+		}
+
+		x, err := sf.GlyphIndex(&buf, r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("glyph %q: %v", r, err)
+		}
+		if x == 0 {
+			// not in the font
+			continue
+		}
+
+		adv, err := sf.GlyphAdvance(&buf, x, ppem, font.HintingNone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("glyph %q: %v", r, err)
+		}
+
+		segs, err := sf.LoadGlyph(&buf, x, ppem, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("glyph %q: %v", r, err)
+		}
+
+		mask, offset := rasterGlyph(segs)
+		if mask != nil {
+			if err := a.Add(string(r), mask); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		f.Glyphs[r] = Glyph{Offset: offset, Advance: fixedFloat(adv)}
+		index[r] = x
+		found = append(found, r)
+	}
+
+	// fonts without kerning report no adjustments or ErrNotFound
+	for _, r0 := range found {
+		for _, r1 := range found {
+			k, err := sf.Kern(&buf, index[r0], index[r1], ppem, font.HintingNone)
+			if err == nil && k != 0 {
+				f.kerning[[2]rune{r0, r1}] = fixedFloat(k)
+			}
+		}
+	}
+
+	pages, err := a.Pack()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pages) > 1 {
+		return nil, nil, fmt.Errorf("glyphs at size %v do not fit in a %vx%v texture", size, fontPageSize, fontPageSize)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	if len(pages) == 1 {
+		img = pages[0].Image
+		for name, r := range pages[0].Sprites {
+			f.Sprites[name] = atlasRect(r)
+		}
+	}
+
+	return f, img, nil
+}
+
+// rasterGlyph draws the outline of a glyph into an alpha mask covering it and
+// returns the mask with the offset of its top left corner from the glyph
+// origin. Blank glyphs return a nil mask.
+func rasterGlyph(segs []sfnt.Segment) (*image.Alpha, Vec) {
+	if len(segs) == 0 {
+		return nil, Vec{}
+	}
+
+	// the control points enclose the outline
+	lo := fixed.Point26_6{X: math.MaxInt32, Y: math.MaxInt32}
+	hi := fixed.Point26_6{X: math.MinInt32, Y: math.MinInt32}
+	for _, s := range segs {
+		n := 1
+		switch s.Op {
+		case sfnt.SegmentOpQuadTo:
+			n = 2
+		case sfnt.SegmentOpCubeTo:
+			n = 3
+		}
+
+		for _, p := range s.Args[:n] {
+			if p.X < lo.X {
+				lo.X = p.X
+			}
+			if p.Y < lo.Y {
+				lo.Y = p.Y
+			}
+			if p.X > hi.X {
+				hi.X = p.X
+			}
+			if p.Y > hi.Y {
+				hi.Y = p.Y
+			}
+		}
+	}
+
+	x0, y0 := lo.X.Floor(), lo.Y.Floor()
+	w, h := hi.X.Ceil()-x0, hi.Y.Ceil()-y0
+	if w <= 0 || h <= 0 {
+		return nil, Vec{}
+	}
+
+	ox, oy := float32(x0), float32(y0)
+	pt := func(p fixed.Point26_6) (float32, float32) {
+		return fixedFloat(p.X) - ox, fixedFloat(p.Y) - oy
+	}
+
+	r := vector.NewRasterizer(w, h)
+	r.DrawOp = draw.Src
+	for _, s := range segs {
+		x1, y1 := pt(s.Args[0])
+		x2, y2 := pt(s.Args[1])
+		x3, y3 := pt(s.Args[2])
+
+		switch s.Op {
+		case sfnt.SegmentOpMoveTo:
+			r.MoveTo(x1, y1)
+		case sfnt.SegmentOpLineTo:
+			r.LineTo(x1, y1)
+		case sfnt.SegmentOpQuadTo:
+			r.QuadTo(x1, y1, x2, y2)
+		case sfnt.SegmentOpCubeTo:
+			r.CubeTo(x1, y1, x2, y2, x3, y3)
+		}
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	r.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	return mask, Vec{ox, oy}
+}
+
+// fixedFloat converts a 26.6 fixed point value to a float
+func fixedFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
+
+// Kern returns the horizontal adjustment between two runes drawn next to each
+// other, positive values moving them apart
+func (f *Font) Kern(r0, r1 rune) float32 {
+	return f.kerning[[2]rune{r0, r1}]
+}
+
+// Measure returns the size of text laid out with the font: the advance of the
+// longest line and the height of all lines. Lines are separated by "\n";
+// runes missing from the font are skipped.
+func (f *Font) Measure(text string) Vec {
+	var w, line float32
+	lines := 1
+
+	prev := rune(-1)
+	for _, r := range text {
+		if r == '\n' {
+			lines++
+			line = 0
+			prev = -1
+			continue
+		}
+
+		g, ok := f.Glyphs[r]
+		if !ok {
+			continue
+		}
+
+		if prev >= 0 {
+			line += f.Kern(prev, r)
+		}
+		line += g.Advance
+		prev = r
+
+		if line > w {
+			w = line
+		}
+	}
+
+	return Vec{w, f.Ascent + f.Descent + float32(lines-1)*f.LineHeight}
+}
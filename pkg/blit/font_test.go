@@ -0,0 +1,166 @@
+package blit
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestGenFont(t *testing.T) {
+	f, img, err := genFont(goregular.TTF, 32, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Size != 32 || f.Ascent <= 0 || f.Descent <= 0 || f.LineHeight < f.Ascent+f.Descent {
+		t.Errorf("metrics: size %v, ascent %v, descent %v, line height %v", f.Size, f.Ascent, f.Descent, f.LineHeight)
+	}
+
+	for _, r := range defaultFontRunes() {
+		if _, ok := f.Glyphs[r]; !ok {
+			t.Errorf("glyph %q missing", r)
+		}
+	}
+
+	b := img.Bounds()
+	for name, r := range f.Sprites {
+		if r.X() < 0 || r.Y() < 0 || r.X()+r.W() > float32(b.Dx()) || r.Y()+r.H() > float32(b.Dy()) {
+			t.Errorf("sprite %q %v is outside the %vx%v glyph image", name, r, b.Dx(), b.Dy())
+		}
+	}
+
+	// blank glyphs advance without a sprite
+	space := f.Glyphs[' ']
+	if _, ok := f.Sprites[" "]; ok || space.Advance <= 0 {
+		t.Errorf("space has sprite %v and advance %v", ok, space.Advance)
+	}
+
+	// "A" stands on the baseline and reaches about the cap height
+	a, ok := f.Sprites["A"]
+	if !ok {
+		t.Fatalf("no sprite for A")
+	}
+	g := f.Glyphs['A']
+	if bottom := g.Offset[1] + a.H(); bottom < -1 || bottom > 1 {
+		t.Errorf("A ends %v pixels below the baseline", bottom)
+	}
+	if g.Offset[1] > -f.Size/2 || g.Offset[1] < -f.Ascent {
+		t.Errorf("A starts %v pixels above the baseline, ascent %v", -g.Offset[1], f.Ascent)
+	}
+
+	// "g" descends below the baseline
+	if r, g := f.Sprites["g"], f.Glyphs['g']; g.Offset[1]+r.H() < 2 {
+		t.Errorf("g ends %v pixels below the baseline", g.Offset[1]+r.H())
+	}
+
+	// the glyphs are white, premultiplied by their coverage
+	opaque := 0
+	for y := int(a.Y()); y < int(a.Y()+a.H()); y++ {
+		for x := int(a.X()); x < int(a.X()+a.W()); x++ {
+			c := img.RGBAAt(x, y)
+			if c.R != c.A || c.G != c.A || c.B != c.A {
+				t.Fatalf("A is %v at (%v, %v), want white", c, x, y)
+			}
+			if c.A == 255 {
+				opaque++
+			}
+		}
+	}
+	if opaque == 0 {
+		t.Errorf("A has no opaque pixels")
+	}
+}
+
+func TestGenFontRunes(t *testing.T) {
+	// duplicates are rasterized once, runes missing from the font are skipped
+	f, _, err := genFont(goregular.TTF, 12, "aab一")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Glyphs) != 2 || len(f.Sprites) != 2 {
+		t.Errorf("got %v glyphs and %v sprites, want 2", len(f.Glyphs), len(f.Sprites))
+	}
+
+	if _, ok := f.Glyphs['一']; ok {
+		t.Errorf("missing rune has a glyph")
+	}
+
+	// an empty set of glyphs still has an image
+	f, img, err := genFont(goregular.TTF, 12, "一")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Glyphs) != 0 || img.Bounds().Empty() {
+		t.Errorf("got %v glyphs and a %v image", len(f.Glyphs), img.Bounds())
+	}
+}
+
+func TestGenFontErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		size  float32
+		runes string
+	}{
+		{"zero size", goregular.TTF, 0, ""},
+		{"negative size", goregular.TTF, -12, ""},
+		{"not a font", []byte("not a font"), 12, ""},
+		{"too large", goregular.TTF, 1500, "MWOQ@%&GHN"},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := genFont(tt.data, tt.size, tt.runes); err == nil {
+			t.Errorf("%v: genFont succeeded", tt.name)
+		}
+	}
+}
+
+func TestFontMeasure(t *testing.T) {
+	f, _, err := genFont(goregular.TTF, 16, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Go Regular has no kerning table
+	f.kerning[[2]rune{'A', 'V'}] = -1.5
+
+	adv := func(s string) float32 {
+		var w float32
+		for i, r := range []rune(s) {
+			if i > 0 {
+				w += f.Kern([]rune(s)[i-1], r)
+			}
+			w += f.Glyphs[r].Advance
+		}
+		return w
+	}
+
+	height := f.Ascent + f.Descent
+
+	tests := []struct {
+		text string
+		want Vec
+	}{
+		{"", Vec{0, height}},
+		{"a", Vec{f.Glyphs['a'].Advance, height}},
+		{"AVAV", Vec{adv("AVAV"), height}},
+		{"AV", Vec{f.Glyphs['A'].Advance + f.Glyphs['V'].Advance - 1.5, height}},
+		{"ab\nabcd\nc", Vec{adv("abcd"), height + 2*f.LineHeight}},
+		{"a一b", Vec{adv("ab"), height}},
+		{strings.Repeat(" ", 3), Vec{3 * f.Glyphs[' '].Advance, height}},
+	}
+
+	for _, tt := range tests {
+		if got := f.Measure(tt.text); !vecWithin(got, tt.want, 1e-3) {
+			t.Errorf("Measure(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRasterGlyphBlank(t *testing.T) {
+	if mask, offset := rasterGlyph(nil); mask != nil || offset != (Vec{}) {
+		t.Errorf("rasterGlyph(nil) = %v, %v", mask, offset)
+	}
+}
@@ -0,0 +1,92 @@
+package blit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Grid describes a spritesheet made of equally sized tiles. Tiles are indexed
+// in row-major order starting at the top left.
+type Grid struct {
+	TileW   int `json:"width"`   // tile width in pixels
+	TileH   int `json:"height"`  // tile height in pixels
+	Margin  int `json:"margin"`  // pixels between the image border and the tiles
+	Spacing int `json:"spacing"` // pixels between neighbouring tiles
+	Cols    int `json:"columns"` // number of columns, derived from the image width if zero
+	Rows    int `json:"rows"`    // number of rows, derived from the image height if zero
+
+	// Names names tiles by index. Tiles without an entry (or with an empty one)
+	// are named by Pattern.
+	Names []string `json:"names"`
+
+	// Pattern names tiles by replacing "{index}", "{col}" and "{row}". Tiles
+	// are not named if both Names and Pattern are empty.
+	Pattern string `json:"pattern"`
+}
+
+// fit derives the number of columns and rows from the image size where not
+// set and checks that the grid lies inside the image
+func (g *Grid) fit(w, h int) error {
+	if g.TileW <= 0 || g.TileH <= 0 {
+		return fmt.Errorf("invalid grid tile size: %vx%v", g.TileW, g.TileH)
+	}
+	if g.Margin < 0 || g.Spacing < 0 {
+		return fmt.Errorf("invalid grid margin or spacing: %v, %v", g.Margin, g.Spacing)
+	}
+
+	if g.Cols <= 0 {
+		g.Cols = (w - 2*g.Margin + g.Spacing) / (g.TileW + g.Spacing)
+	}
+	if g.Rows <= 0 {
+		g.Rows = (h - 2*g.Margin + g.Spacing) / (g.TileH + g.Spacing)
+	}
+
+	if g.Cols <= 0 || g.Rows <= 0 {
+		return fmt.Errorf("grid tiles do not fit the %vx%v image", w, h)
+	}
+
+	r := g.Cell(g.Cols-1, g.Rows-1)
+	if int(r.X()+r.W()) > w || int(r.Y()+r.H()) > h {
+		return fmt.Errorf("grid of %vx%v tiles exceeds the %vx%v image", g.Cols, g.Rows, w, h)
+	}
+
+	return nil
+}
+
+// Len returns the number of tiles in the grid
+func (g Grid) Len() int {
+	return g.Cols * g.Rows
+}
+
+// Cell returns the rect of the tile at the given column and row
+func (g Grid) Cell(col, row int) Rect {
+	return Rect{
+		float32(g.Margin + col*(g.TileW+g.Spacing)),
+		float32(g.Margin + row*(g.TileH+g.Spacing)),
+		float32(g.TileW),
+		float32(g.TileH),
+	}
+}
+
+// Index returns the rect of the tile at the given index
+func (g Grid) Index(i int) Rect {
+	return g.Cell(i%g.Cols, i/g.Cols)
+}
+
+// Name returns the name of the tile at the given index, empty if unnamed
+func (g Grid) Name(i int) string {
+	if i < len(g.Names) && g.Names[i] != "" {
+		return g.Names[i]
+	}
+
+	if g.Pattern == "" {
+		return ""
+	}
+
+	return strings.NewReplacer(
+		"{index}", strconv.Itoa(i),
+		"{col}", strconv.Itoa(i%g.Cols),
+		"{row}", strconv.Itoa(i/g.Cols),
+	).Replace(g.Pattern)
+}
@@ -0,0 +1,129 @@
+package blit
+
+import "testing"
+
+func TestGridFit(t *testing.T) {
+	tests := []struct {
+		name       string
+		grid       Grid
+		w, h       int
+		cols, rows int
+		err        bool
+	}{
+		{"whole tiles", Grid{TileW: 16, TileH: 16}, 64, 32, 4, 2, false},
+		{"partial tiles", Grid{TileW: 16, TileH: 16}, 70, 47, 4, 2, false},
+		{"non-square tiles", Grid{TileW: 8, TileH: 24}, 64, 48, 8, 2, false},
+		{"margin and spacing", Grid{TileW: 16, TileH: 16, Margin: 2, Spacing: 1}, 71, 37, 4, 2, false},
+		{"partial with spacing", Grid{TileW: 16, TileH: 16, Margin: 2, Spacing: 1}, 70, 36, 3, 1, false},
+		{"trailing spacing", Grid{TileW: 16, TileH: 16, Spacing: 4}, 76, 16, 4, 1, false},
+		{"single tile", Grid{TileW: 16, TileH: 16, Margin: 3}, 22, 22, 1, 1, false},
+		{"fixed columns", Grid{TileW: 16, TileH: 16, Cols: 2}, 64, 32, 2, 2, false},
+		{"fixed rows", Grid{TileW: 16, TileH: 16, Rows: 1}, 64, 32, 4, 1, false},
+		{"too many columns", Grid{TileW: 16, TileH: 16, Cols: 5}, 64, 32, 5, 2, true},
+		{"too many rows", Grid{TileW: 16, TileH: 16, Margin: 1, Rows: 2}, 64, 32, 3, 2, true},
+		{"tile larger than image", Grid{TileW: 32, TileH: 32}, 64, 16, 2, 0, true},
+		{"margin larger than image", Grid{TileW: 16, TileH: 16, Margin: 8}, 24, 24, 0, 0, true},
+		{"zero tile size", Grid{TileW: 0, TileH: 16}, 64, 32, 0, 0, true},
+		{"negative margin", Grid{TileW: 16, TileH: 16, Margin: -1}, 64, 32, 0, 0, true},
+		{"negative spacing", Grid{TileW: 16, TileH: 16, Spacing: -2}, 64, 32, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		g := tt.grid
+		err := g.fit(tt.w, tt.h)
+
+		if (err != nil) != tt.err {
+			t.Errorf("%v: fit(%v, %v) = %v, want error %v", tt.name, tt.w, tt.h, err, tt.err)
+			continue
+		}
+
+		if g.Cols != tt.cols || g.Rows != tt.rows {
+			t.Errorf("%v: fit(%v, %v) gives %vx%v tiles, want %vx%v", tt.name, tt.w, tt.h, g.Cols, g.Rows, tt.cols, tt.rows)
+		}
+
+		if err == nil {
+			// every tile lies inside the image
+			r := g.Index(g.Len() - 1)
+			if r.X()+r.W() > float32(tt.w) || r.Y()+r.H() > float32(tt.h) {
+				t.Errorf("%v: last tile %v is outside the %vx%v image", tt.name, r, tt.w, tt.h)
+			}
+		}
+	}
+}
+
+func TestGridCell(t *testing.T) {
+	g := Grid{TileW: 16, TileH: 8, Margin: 2, Spacing: 1, Cols: 4, Rows: 3}
+
+	tests := []struct {
+		col, row int
+		want     Rect
+	}{
+		{0, 0, Rect{2, 2, 16, 8}},
+		{1, 0, Rect{19, 2, 16, 8}},
+		{0, 1, Rect{2, 11, 16, 8}},
+		{3, 2, Rect{53, 20, 16, 8}},
+	}
+
+	for _, tt := range tests {
+		if got := g.Cell(tt.col, tt.row); got != tt.want {
+			t.Errorf("Cell(%v, %v) = %v, want %v", tt.col, tt.row, got, tt.want)
+		}
+
+		// tiles are indexed in row-major order
+		i := tt.row*g.Cols + tt.col
+		if got := g.Index(i); got != tt.want {
+			t.Errorf("Index(%v) = %v, want %v", i, got, tt.want)
+		}
+	}
+
+	if n := g.Len(); n != 12 {
+		t.Errorf("Len() = %v, want 12", n)
+	}
+}
+
+func TestGridName(t *testing.T) {
+	tests := []struct {
+		name string
+		grid Grid
+		want []string
+	}{
+		{
+			"unnamed",
+			Grid{Cols: 3, Rows: 2},
+			[]string{"", "", "", "", "", ""},
+		},
+		{
+			"pattern",
+			Grid{Cols: 3, Rows: 2, Pattern: "tile_{index}_{col}_{row}"},
+			[]string{"tile_0_0_0", "tile_1_1_0", "tile_2_2_0", "tile_3_0_1", "tile_4_1_1", "tile_5_2_1"},
+		},
+		{
+			"repeated placeholders",
+			Grid{Cols: 2, Rows: 1, Pattern: "{row}{row}-{index}"},
+			[]string{"00-0", "00-1"},
+		},
+		{
+			"names",
+			Grid{Cols: 2, Rows: 2, Names: []string{"a", "b", "c", "d", "extra"}},
+			[]string{"a", "b", "c", "d"},
+		},
+		{
+			"names and pattern",
+			Grid{Cols: 2, Rows: 2, Names: []string{"idle", "", "jump"}, Pattern: "t{index}"},
+			[]string{"idle", "t1", "jump", "t3"},
+		},
+		{
+			"names only",
+			Grid{Cols: 2, Rows: 2, Names: []string{"idle", "", "jump"}},
+			[]string{"idle", "", "jump", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := tt.grid.Name(i); got != want {
+				t.Errorf("%v: Name(%v) = %q, want %q", tt.name, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package blit
+
+import (
+	"image/color"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+const instanceSize = 24 // modl (16), uv rect (4), tint (4)
+
+var (
+	quadUnit = []float32{
+		// x, y, u, v
+		-0.5, +0.5, 0, 0, // top left
+		-0.5, -0.5, 0, 1, // bottom left
+		+0.5, -0.5, 1, 1, // bottom right
+		+0.5, +0.5, 1, 0, // top right
+		-0.5, +0.5, 0, 0, // top left
+		+0.5, -0.5, 1, 1, // bottom right
+	}
+)
+
+// Instances draws many textured quads sharing one texture with a single
+// instanced draw call. Each instance has its own model matrix, texture rect
+// and tint.
+//
+// Programs drawn through Instances must use the vertex layout of
+// bgl.InstanceVert ("tex", "modl", "rect" and "tint").
+type Instances struct {
+	shader *bgl.Program
+
+	Tex *bgl.Texture
+
+	quad *bgl.VBO
+	inst *bgl.VBO
+	vao  *bgl.VAO
+
+	data  []float32
+	dirty bool
+}
+
+// NewInstances creates an empty set of instances drawn with the given program
+// and texture
+func NewInstances(shader *bgl.Program, texture *bgl.Texture) *Instances {
+	in := &Instances{
+		shader: shader,
+		Tex:    texture,
+	}
+
+	in.quad = bgl.NewVBO()
+	in.quad.Usage = bgl.StaticDraw
+	in.quad.SetData(quadUnit)
+
+	in.inst = bgl.NewVBO()
+	in.inst.Usage = bgl.DynamicDraw
+
+	in.vao = bgl.NewInstancedVAO(
+		bgl.AttrBuffer{
+			VBO: in.quad,
+			AttrFormat: bgl.AttrFormat{
+				{Name: "tex", Type: bgl.Vec4f},
+			}.Locate(shader),
+		},
+		bgl.AttrBuffer{
+			VBO: in.inst,
+			AttrFormat: bgl.AttrFormat{
+				{Name: "modl", Type: bgl.Mat4f},
+				{Name: "rect", Type: bgl.Vec4f},
+				{Name: "tint", Type: bgl.Vec4f},
+			}.Locate(shader),
+			Divisor: 1,
+		},
+	)
+
+	in.inst.Unbind()
+
+	return in
+}
+
+// Len returns the number of instances
+func (in *Instances) Len() int {
+	return len(in.data) / instanceSize
+}
+
+// Add adds an instance and returns its index
+func (in *Instances) Add(rect Rect, m Mat, tint color.RGBA) int {
+	in.data = append(in.data, make([]float32, instanceSize)...)
+
+	i := in.Len() - 1
+	in.Set(i, rect, m, tint)
+
+	return i
+}
+
+// Set replaces the instance at index i
+func (in *Instances) Set(i int, rect Rect, m Mat, tint color.RGBA) {
+	d := in.data[i*instanceSize : (i+1)*instanceSize]
+
+	copy(d[0:16], m[:])
+
+	min := in.Tex.UV(rect.X(), rect.Y())
+	max := in.Tex.UV(rect.X()+rect.W(), rect.Y()+rect.H())
+	d[16] = min[0]
+	d[17] = min[1]
+	d[18] = max[0] - min[0]
+	d[19] = max[1] - min[1]
+
+	d[20] = float32(tint.R) / 255.0
+	d[21] = float32(tint.G) / 255.0
+	d[22] = float32(tint.B) / 255.0
+	d[23] = float32(tint.A) / 255.0
+
+	in.dirty = true
+}
+
+// SetMat replaces the model matrix of the instance at index i
+func (in *Instances) SetMat(i int, m Mat) {
+	copy(in.data[i*instanceSize:], m[:])
+
+	in.dirty = true
+}
+
+// Clear removes all instances
+func (in *Instances) Clear() {
+	in.data = in.data[:0]
+
+	in.dirty = true
+}
+
+// Draw uploads any changed instance data and draws all instances
+func (in *Instances) Draw() {
+	if in.Len() == 0 {
+		return
+	}
+
+	if in.dirty {
+		in.inst.SetData(in.data)
+		in.inst.Unbind()
+		in.dirty = false
+	}
+
+	in.shader.Bind()
+	in.Tex.Bind()
+	in.vao.Bind()
+	in.quad.DrawInstanced(len(quadUnit)/4, in.Len())
+	in.quad.Unbind()
+	in.vao.Unbind()
+	in.Tex.Unbind()
+	in.shader.Unbind()
+}
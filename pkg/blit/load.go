@@ -0,0 +1,172 @@
+package blit
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png" // DecodeImage always supports PNG
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/octalide/blit/pkg/bgl"
+)
+
+// osFS opens plain operating system paths, allowing the path based loaders to
+// share the fs.FS code paths. Unlike os.DirFS it accepts absolute and
+// relative paths.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// DecodeImage decodes an image in any format registered with the image
+// package and converts it to RGBA with its origin at (0, 0). PNG is always
+// registered; import other decoders (e.g. _ "image/jpeg") to enable them.
+func DecodeImage(r io.Reader) (*image.RGBA, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rebase(rgba), nil
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+
+	return rgba, nil
+}
+
+// LoadImageFS loads and decodes an image from fsys (see DecodeImage)
+func LoadImageFS(fsys fs.FS, name string) (*image.RGBA, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := DecodeImage(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %v: %w", name, err)
+	}
+
+	return img, nil
+}
+
+// LoadTextureFS loads an image from fsys into a texture
+func LoadTextureFS(fsys fs.FS, name string, filter bgl.Filter) (*bgl.Texture, error) {
+	img, err := LoadImageFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return bgl.NewTexture(img, filter), nil
+}
+
+// LoadTexture loads an image file into a texture
+func LoadTexture(path string, filter bgl.Filter) (*bgl.Texture, error) {
+	return LoadTextureFS(osFS{}, path, filter)
+}
+
+// LoadSpritesheetFS loads a spritesheet image and its JSON description from
+// fsys, either in our spritesheet format or exported by Aseprite
+func LoadSpritesheetFS(fsys fs.FS, imgName, descName string) (*Spritesheet, error) {
+	img, err := LoadImageFS(fsys, imgName)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := fs.ReadFile(fsys, descName)
+	if err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+
+	ss, err := parseSpritesheet(desc, descName, b.Dx(), b.Dy())
+	if err != nil {
+		return nil, err
+	}
+
+	ss.Texture = bgl.NewTexture(img, ss.Filter)
+
+	return ss, nil
+}
+
+// parseSpritesheet creates a spritesheet without texture from a description in
+// either format for an image of the given size
+func parseSpritesheet(desc []byte, descName string, w, h int) (*Spritesheet, error) {
+	if isAseprite(desc) {
+		return genAseprite(desc, w, h)
+	}
+
+	sheet, diags := ParseSheetDesc(desc)
+	if err := diags.Err(); err != nil {
+		// report the problems of the entries that did decode as well
+		diags = append(diags, sheet.Validate(w, h)...)
+
+		return nil, fmt.Errorf("invalid spritesheet %v:\n%w", descName, diags.Err())
+	}
+
+	ss, err := genSpritesheet(sheet, w, h)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spritesheet %v:\n%w", descName, err)
+	}
+
+	ss.Warnings = append(diags, ss.Warnings...)
+
+	return ss, nil
+}
+
+// LoadSpritesheet loads a spritesheet image and its JSON description, either
+// in our spritesheet format or exported by Aseprite
+func LoadSpritesheet(imgPath, descPath string) (*Spritesheet, error) {
+	return LoadSpritesheetFS(osFS{}, imgPath, descPath)
+}
+
+// LoadAsepriteFS loads an Aseprite JSON export from fsys along with the image
+// it references, resolved relative to the JSON file
+func LoadAsepriteFS(fsys fs.FS, descName string) (*Spritesheet, error) {
+	return loadAseprite(fsys, descName, func(image string) string {
+		return path.Join(path.Dir(descName), image)
+	})
+}
+
+// LoadAseprite loads an Aseprite JSON export along with the image it
+// references, resolved relative to the JSON file
+func LoadAseprite(descPath string) (*Spritesheet, error) {
+	return loadAseprite(osFS{}, descPath, func(image string) string {
+		return filepath.Join(filepath.Dir(descPath), image)
+	})
+}
+
+// loadAseprite loads an Aseprite export, resolving the image name with resolve
+func loadAseprite(fsys fs.FS, descName string, resolve func(image string) string) (*Spritesheet, error) {
+	desc, err := fs.ReadFile(fsys, descName)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheet asepriteSheet
+	if err := json.Unmarshal(desc, &sheet); err != nil {
+		return nil, fmt.Errorf("invalid aseprite json: %v", err)
+	}
+
+	if sheet.Meta.Image == "" {
+		return nil, fmt.Errorf("aseprite json does not reference an image: %v", descName)
+	}
+
+	img, err := LoadImageFS(fsys, resolve(sheet.Meta.Image))
+	if err != nil {
+		return nil, err
+	}
+
+	return GenAsepriteSpritesheet(img, desc)
+}
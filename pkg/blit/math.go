@@ -209,32 +209,21 @@ func (m Mat) Pos(v Vec) Mat {
 	})
 }
 
+// Scale scales a matrix by a scale vector
+func (m Mat) Scale(v Vec) Mat {
+	return m.Mul(Mat{
+		v.X(), 0, 0, 0,
+		0, v.Y(), 0, 0,
+		0, 0, v.Z(), 0,
+		0, 0, 0, 1,
+	})
+}
+
 // Det gets the determinant of a 4x4 matrix
 func (m Mat) Det() float32 {
-	return m[0]*m[5]*m[10]*m[15] +
-		m[0]*m[6]*m[9]*m[14] +
-		m[0]*m[7]*m[8]*m[13] +
-		m[1]*m[4]*m[10]*m[15] +
-		m[1]*m[6]*m[8]*m[12] +
-		m[1]*m[7]*m[9]*m[14] +
-		m[2]*m[4]*m[9]*m[15] +
-		m[2]*m[5]*m[8]*m[13] +
-		m[2]*m[7]*m[10]*m[12] +
-		m[3]*m[4]*m[8]*m[14] +
-		m[3]*m[5]*m[9]*m[12] +
-		m[3]*m[6]*m[10]*m[13] -
-		m[0]*m[5]*m[9]*m[15] -
-		m[0]*m[6]*m[10]*m[13] -
-		m[0]*m[7]*m[11]*m[12] -
-		m[1]*m[4]*m[10]*m[14] -
-		m[1]*m[6]*m[11]*m[12] -
-		m[1]*m[7]*m[8]*m[13] -
-		m[2]*m[4]*m[11]*m[14] -
-		m[2]*m[5]*m[8]*m[12] -
-		m[2]*m[7]*m[9]*m[13] -
-		m[3]*m[4]*m[9]*m[14] -
-		m[3]*m[5]*m[10]*m[12] -
-		m[3]*m[6]*m[8]*m[13]
+	a := m.adj()
+
+	return m[0]*a[0] + m[1]*a[4] + m[2]*a[8] + m[3]*a[12]
 }
 
 // Mul performs a matrix product
@@ -259,44 +248,204 @@ func (m Mat) Mul(m2 Mat) Mat {
 	}
 }
 
-// Rot rotates a matrix along the z axis
+// Rot rotates a matrix clockwise along the z axis, the 2D rotation direction
+// of Orienter.R. See RotZ for the counter-clockwise rotation used by RotX,
+// RotY, RotAxis and Quat.
 func (m Mat) Rot(rad float32) Mat {
+	return m.RotZ(-rad)
+}
+
+// RotX rotates a matrix counter-clockwise along the x axis
+func (m Mat) RotX(rad float32) Mat {
+	s, c := math.Sincos(float64(rad))
+	sin := float32(s)
+	cos := float32(c)
+
+	return m.Mul(Mat{
+		1, 0, 0, 0,
+		0, cos, sin, 0,
+		0, -sin, cos, 0,
+		0, 0, 0, 1,
+	})
+}
+
+// RotY rotates a matrix counter-clockwise along the y axis
+func (m Mat) RotY(rad float32) Mat {
+	s, c := math.Sincos(float64(rad))
+	sin := float32(s)
+	cos := float32(c)
+
+	return m.Mul(Mat{
+		cos, 0, -sin, 0,
+		0, 1, 0, 0,
+		sin, 0, cos, 0,
+		0, 0, 0, 1,
+	})
+}
+
+// RotZ rotates a matrix counter-clockwise along the z axis
+func (m Mat) RotZ(rad float32) Mat {
 	s, c := math.Sincos(float64(rad))
 	sin := float32(s)
 	cos := float32(c)
 
 	return m.Mul(Mat{
-		cos, -sin, 0, 0,
-		sin, cos, 0, 0,
+		cos, sin, 0, 0,
+		-sin, cos, 0, 0,
 		0, 0, 1, 0,
 		0, 0, 0, 1,
 	})
 }
 
+// RotAxis rotates a matrix counter-clockwise along an arbitrary axis
+func (m Mat) RotAxis(axis Vec, rad float32) Mat {
+	axis[3] = 0
+	a := axis.Nrm()
+	x, y, z := a[0], a[1], a[2]
+
+	s, c := math.Sincos(float64(rad))
+	sin := float32(s)
+	cos := float32(c)
+	k := 1 - cos
+
+	return m.Mul(Mat{
+		x*x*k + cos, y*x*k + z*sin, z*x*k - y*sin, 0,
+		x*y*k - z*sin, y*y*k + cos, z*y*k + x*sin, 0,
+		x*z*k + y*sin, y*z*k - x*sin, z*z*k + cos, 0,
+		0, 0, 0, 1,
+	})
+}
+
+// Transpose returns the transpose of the matrix
+func (m Mat) Transpose() Mat {
+	return Mat{
+		m[0], m[4], m[8], m[12],
+		m[1], m[5], m[9], m[13],
+		m[2], m[6], m[10], m[14],
+		m[3], m[7], m[11], m[15],
+	}
+}
+
+// Normal returns the normal matrix (the inverse transpose of the upper 3x3)
+// used to transform normals by a model matrix
+func (m Mat) Normal() Mat {
+	n := Mat{
+		m[0], m[1], m[2], 0,
+		m[4], m[5], m[6], 0,
+		m[8], m[9], m[10], 0,
+		0, 0, 0, 1,
+	}
+
+	return n.Inv().Transpose()
+}
+
+// Decompose splits an affine matrix into a position, rotation and scale such
+// that m == Ident().Pos(pos).Mul(rot.Mat()).Scale(scale). Any shear is lost.
+func (m Mat) Decompose() (pos Vec, rot Quat, scale Vec) {
+	x := Vec{m[0], m[1], m[2]}
+	y := Vec{m[4], m[5], m[6]}
+	z := Vec{m[8], m[9], m[10]}
+
+	scale = Vec{x.Len(), y.Len(), z.Len(), 1}
+	if x.Crs(y).Dot(z) < 0 {
+		scale[0] = -scale[0]
+	}
+
+	x, y, z = x.Div(scale[0]), y.Div(scale[1]), z.Div(scale[2])
+	rot = QuatMat(Mat{
+		x[0], x[1], x[2], 0,
+		y[0], y[1], y[2], 0,
+		z[0], z[1], z[2], 0,
+		0, 0, 0, 1,
+	})
+
+	pos = Vec{m[12], m[13], m[14]}
+
+	return
+}
+
+// Transform transforms a point by the matrix, dividing by the resulting w
+// component for projective matrices
+func (m Mat) Transform(v Vec) Vec {
+	m.TransformIn(&v)
+	return v
+}
+
+// TransformDir transforms a direction by the matrix, ignoring translation
+func (m Mat) TransformDir(v Vec) Vec {
+	return Vec{
+		v[0]*m[0] + v[1]*m[4] + v[2]*m[8],
+		v[0]*m[1] + v[1]*m[5] + v[2]*m[9],
+		v[0]*m[2] + v[1]*m[6] + v[2]*m[10],
+	}
+}
+
+// TransformIn transforms a point by the matrix in place, dividing by the
+// resulting w component for projective matrices
+func (m *Mat) TransformIn(v *Vec) {
+	x, y, z := v[0], v[1], v[2]
+
+	v[0] = x*m[0] + y*m[4] + z*m[8] + m[12]
+	v[1] = x*m[1] + y*m[5] + z*m[9] + m[13]
+	v[2] = x*m[2] + y*m[6] + z*m[10] + m[14]
+	w := x*m[3] + y*m[7] + z*m[11] + m[15]
+
+	if w != 1 && w != 0 {
+		v[0] /= w
+		v[1] /= w
+		v[2] /= w
+	}
+
+	v[3] = 0
+}
+
+// PosIn transforms a matrix by a position vector in place
+func (m *Mat) PosIn(v Vec) {
+	for r := 0; r < 4; r++ {
+		m[12+r] += m[r]*v[0] + m[4+r]*v[1] + m[8+r]*v[2]
+	}
+}
+
+// ScaleIn scales a matrix by a scale vector in place
+func (m *Mat) ScaleIn(v Vec) {
+	for r := 0; r < 4; r++ {
+		m[r] *= v[0]
+		m[4+r] *= v[1]
+		m[8+r] *= v[2]
+	}
+}
+
 // Inv inverts the matrix
 func (m Mat) Inv() Mat {
-	d := m.Det()
+	a := m.adj()
+
+	d := m[0]*a[0] + m[1]*a[4] + m[2]*a[8] + m[3]*a[12]
 	if d == 0 {
 		return m
 	}
 
+	return a.Scl(1 / d)
+}
+
+// adj returns the adjugate of the matrix
+func (m Mat) adj() Mat {
 	return Mat{
-		m[5]*m[10]*m[15] + m[6]*m[11]*m[13] + m[7]*m[9]*m[14] - m[5]*m[11]*m[14] - m[6]*m[9]*m[15] - m[7]*m[10]*m[13],
-		m[1]*m[11]*m[14] + m[2]*m[9]*m[15] + m[3]*m[10]*m[13] - m[1]*m[10]*m[15] - m[2]*m[11]*m[13] - m[3]*m[9]*m[14],
-		m[1]*m[6]*m[15] + m[2]*m[7]*m[13] + m[3]*m[5]*m[14] - m[1]*m[7]*m[14] - m[2]*m[5]*m[15] - m[3]*m[6]*m[13],
-		m[1]*m[7]*m[10] + m[2]*m[5]*m[11] + m[3]*m[6]*m[9] - m[1]*m[6]*m[11] - m[2]*m[7]*m[9] - m[3]*m[5]*m[10],
-		m[4]*m[11]*m[14] + m[6]*m[8]*m[15] + m[7]*m[10]*m[12] - m[4]*m[10]*m[15] - m[6]*m[11]*m[12] - m[7]*m[8]*m[14],
-		m[0]*m[10]*m[15] + m[2]*m[11]*m[12] + m[3]*m[8]*m[14] - m[0]*m[11]*m[14] - m[2]*m[8]*m[15] - m[3]*m[10]*m[12],
-		m[0]*m[7]*m[14] + m[2]*m[4]*m[15] + m[3]*m[6]*m[12] - m[0]*m[6]*m[15] - m[2]*m[7]*m[12] - m[3]*m[4]*m[14],
-		m[0]*m[6]*m[11] + m[2]*m[7]*m[8] + m[3]*m[4]*m[10] - m[0]*m[7]*m[10] - m[2]*m[4]*m[11] - m[3]*m[6]*m[8],
-		m[4]*m[9]*m[15] + m[5]*m[11]*m[12] + m[7]*m[8]*m[13] - m[4]*m[11]*m[13] - m[5]*m[8]*m[15] - m[7]*m[9]*m[12],
-		m[0]*m[11]*m[13] + m[1]*m[8]*m[15] + m[3]*m[9]*m[12] - m[0]*m[9]*m[15] - m[1]*m[11]*m[12] - m[3]*m[8]*m[13],
-		m[0]*m[5]*m[15] + m[1]*m[7]*m[12] + m[3]*m[4]*m[13] - m[0]*m[7]*m[13] - m[1]*m[4]*m[15] - m[3]*m[5]*m[12],
-		m[0]*m[7]*m[9] + m[1]*m[4]*m[11] + m[3]*m[5]*m[8] - m[0]*m[5]*m[11] - m[1]*m[7]*m[8] - m[3]*m[4]*m[9],
-		m[4]*m[10]*m[13] + m[5]*m[8]*m[14] + m[6]*m[9]*m[12] - m[4]*m[9]*m[14] - m[5]*m[10]*m[12] - m[6]*m[8]*m[13],
-		m[0]*m[9]*m[14] + m[1]*m[10]*m[12] + m[2]*m[8]*m[13] - m[0]*m[10]*m[13] - m[1]*m[8]*m[14] - m[2]*m[9]*m[12],
-		m[0]*m[6]*m[13] + m[1]*m[4]*m[14] + m[2]*m[5]*m[12] - m[0]*m[4]*m[14] - m[1]*m[6]*m[12] - m[2]*m[5]*m[13],
-		m[0]*m[4]*m[10] + m[1]*m[5]*m[8] + m[2]*m[6]*m[9] - m[0]*m[5]*m[9] - m[1]*m[6]*m[8] - m[2]*m[4]*m[10],
+		m[5]*m[10]*m[15] - m[5]*m[11]*m[14] - m[9]*m[6]*m[15] + m[9]*m[7]*m[14] + m[13]*m[6]*m[11] - m[13]*m[7]*m[10],
+		-m[1]*m[10]*m[15] + m[1]*m[11]*m[14] + m[9]*m[2]*m[15] - m[9]*m[3]*m[14] - m[13]*m[2]*m[11] + m[13]*m[3]*m[10],
+		m[1]*m[6]*m[15] - m[1]*m[7]*m[14] - m[5]*m[2]*m[15] + m[5]*m[3]*m[14] + m[13]*m[2]*m[7] - m[13]*m[3]*m[6],
+		-m[1]*m[6]*m[11] + m[1]*m[7]*m[10] + m[5]*m[2]*m[11] - m[5]*m[3]*m[10] - m[9]*m[2]*m[7] + m[9]*m[3]*m[6],
+		-m[4]*m[10]*m[15] + m[4]*m[11]*m[14] + m[8]*m[6]*m[15] - m[8]*m[7]*m[14] - m[12]*m[6]*m[11] + m[12]*m[7]*m[10],
+		m[0]*m[10]*m[15] - m[0]*m[11]*m[14] - m[8]*m[2]*m[15] + m[8]*m[3]*m[14] + m[12]*m[2]*m[11] - m[12]*m[3]*m[10],
+		-m[0]*m[6]*m[15] + m[0]*m[7]*m[14] + m[4]*m[2]*m[15] - m[4]*m[3]*m[14] - m[12]*m[2]*m[7] + m[12]*m[3]*m[6],
+		m[0]*m[6]*m[11] - m[0]*m[7]*m[10] - m[4]*m[2]*m[11] + m[4]*m[3]*m[10] + m[8]*m[2]*m[7] - m[8]*m[3]*m[6],
+		m[4]*m[9]*m[15] - m[4]*m[11]*m[13] - m[8]*m[5]*m[15] + m[8]*m[7]*m[13] + m[12]*m[5]*m[11] - m[12]*m[7]*m[9],
+		-m[0]*m[9]*m[15] + m[0]*m[11]*m[13] + m[8]*m[1]*m[15] - m[8]*m[3]*m[13] - m[12]*m[1]*m[11] + m[12]*m[3]*m[9],
+		m[0]*m[5]*m[15] - m[0]*m[7]*m[13] - m[4]*m[1]*m[15] + m[4]*m[3]*m[13] + m[12]*m[1]*m[7] - m[12]*m[3]*m[5],
+		-m[0]*m[5]*m[11] + m[0]*m[7]*m[9] + m[4]*m[1]*m[11] - m[4]*m[3]*m[9] - m[8]*m[1]*m[7] + m[8]*m[3]*m[5],
+		-m[4]*m[9]*m[14] + m[4]*m[10]*m[13] + m[8]*m[5]*m[14] - m[8]*m[6]*m[13] - m[12]*m[5]*m[10] + m[12]*m[6]*m[9],
+		m[0]*m[9]*m[14] - m[0]*m[10]*m[13] - m[8]*m[1]*m[14] + m[8]*m[2]*m[13] + m[12]*m[1]*m[10] - m[12]*m[2]*m[9],
+		-m[0]*m[5]*m[14] + m[0]*m[6]*m[13] + m[4]*m[1]*m[14] - m[4]*m[2]*m[13] - m[12]*m[1]*m[6] + m[12]*m[2]*m[5],
+		m[0]*m[5]*m[10] - m[0]*m[6]*m[9] - m[4]*m[1]*m[10] + m[4]*m[2]*m[9] + m[8]*m[1]*m[6] - m[8]*m[2]*m[5],
 	}
 }
 
@@ -0,0 +1,212 @@
+package blit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const mathEps = 1e-4
+
+// testMats are the matrices the math tests transform, invert and decompose
+var testMats = map[string]mgl32.Mat4{
+	"ident":       mgl32.Ident4(),
+	"translate":   mgl32.Translate3D(1, -2, 3),
+	"scale":       mgl32.Scale3D(2, 0.5, -3),
+	"rotate":      mgl32.HomogRotate3D(0.7, mgl32.Vec3{1, 2, 3}.Normalize()),
+	"trs":         mgl32.Translate3D(4, 5, -6).Mul4(mgl32.HomogRotate3DY(1.2)).Mul4(mgl32.Scale3D(2, 3, 4)),
+	"perspective": mgl32.Perspective(1, 1.5, 0.1, 100).Mul4(mgl32.Translate3D(0, 0, -5)),
+	"arbitrary": {
+		2, 1, 0, 0.5,
+		-1, 3, 2, 0,
+		0.5, 0, 1, -1,
+		3, -2, 1, 4,
+	},
+}
+
+var testAngles = []float32{0, 0.3, -1.1, math.Pi / 2, math.Pi, 2.5}
+
+func matNear(m Mat, want mgl32.Mat4) bool {
+	for i := range m {
+		if math.Abs(float64(m[i]-want[i])) > mathEps {
+			return false
+		}
+	}
+
+	return true
+}
+
+func vecNear(v Vec, want mgl32.Vec3) bool {
+	for i := range want {
+		if math.Abs(float64(v[i]-want[i])) > mathEps {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestMatRot(t *testing.T) {
+	base := testMats["trs"]
+
+	tests := []struct {
+		name string
+		rot  func(m Mat, rad float32) Mat
+		want func(rad float32) mgl32.Mat4
+	}{
+		{"RotX", Mat.RotX, mgl32.HomogRotate3DX},
+		{"RotY", Mat.RotY, mgl32.HomogRotate3DY},
+		{"RotZ", Mat.RotZ, mgl32.HomogRotate3DZ},
+		{"Rot", Mat.Rot, func(rad float32) mgl32.Mat4 { return mgl32.HomogRotate3DZ(-rad) }},
+		{"RotAxis", func(m Mat, rad float32) Mat { return m.RotAxis(Vec{1, -2, 0.5}, rad) },
+			func(rad float32) mgl32.Mat4 { return mgl32.HomogRotate3D(rad, mgl32.Vec3{1, -2, 0.5}.Normalize()) }},
+	}
+
+	for _, tt := range tests {
+		for _, a := range testAngles {
+			if got, want := tt.rot(Ident(), a), tt.want(a); !matNear(got, want) {
+				t.Errorf("%v(%v) = %v, want %v", tt.name, a, got, Mat(want))
+			}
+
+			if got, want := tt.rot(Mat(base), a), base.Mul4(tt.want(a)); !matNear(got, want) {
+				t.Errorf("m.%v(%v) = %v, want %v", tt.name, a, got, Mat(want))
+			}
+		}
+	}
+}
+
+func TestPerspective(t *testing.T) {
+	tests := []struct {
+		fov, aspect, near, far float32
+	}{
+		{mgl32.DegToRad(45), 16.0 / 9, 0.1, 100},
+		{mgl32.DegToRad(90), 1, 1, 10},
+		{1.2, 0.5, 0.01, 1000},
+	}
+
+	for _, tt := range tests {
+		got := Perspective(tt.fov, tt.aspect, tt.near, tt.far)
+		want := mgl32.Perspective(tt.fov, tt.aspect, tt.near, tt.far)
+		if !matNear(got, want) {
+			t.Errorf("Perspective(%v, %v, %v, %v) = %v, want %v", tt.fov, tt.aspect, tt.near, tt.far, got, Mat(want))
+		}
+	}
+}
+
+func TestMatInvDet(t *testing.T) {
+	for name, want := range testMats {
+		m := Mat(want)
+
+		if got, d := m.Det(), want.Det(); math.Abs(float64(got-d)) > mathEps*math.Max(1, math.Abs(float64(d))) {
+			t.Errorf("%v: Det() = %v, want %v", name, got, d)
+		}
+
+		if got := m.Inv(); !matNear(got, want.Inv()) {
+			t.Errorf("%v: Inv() = %v, want %v", name, got, Mat(want.Inv()))
+		}
+
+		if got := m.Transpose(); !matNear(got, want.Transpose()) {
+			t.Errorf("%v: Transpose() = %v, want %v", name, got, Mat(want.Transpose()))
+		}
+	}
+}
+
+func TestMatNormal(t *testing.T) {
+	for name, want := range testMats {
+		n := want.Mat3().Inv().Transpose()
+		got := Mat(want).Normal()
+
+		for c := 0; c < 3; c++ {
+			for r := 0; r < 3; r++ {
+				if math.Abs(float64(got[c*4+r]-n[c*3+r])) > mathEps {
+					t.Fatalf("%v: Normal() = %v, want upper 3x3 %v", name, got, n)
+				}
+			}
+		}
+
+		if got[3] != 0 || got[7] != 0 || got[11] != 0 || got[12] != 0 || got[13] != 0 || got[14] != 0 || got[15] != 1 {
+			t.Errorf("%v: Normal() = %v, want no translation or projection", name, got)
+		}
+	}
+}
+
+func TestMatTransform(t *testing.T) {
+	points := []mgl32.Vec3{{0, 0, 0}, {1, 2, 3}, {-4, 0.5, -2}, {0.25, -1, 10}}
+
+	for name, want := range testMats {
+		m := Mat(want)
+
+		for _, p := range points {
+			v := Vec{p[0], p[1], p[2]}
+
+			if got, w := m.Transform(v), mgl32.TransformCoordinate(p, want); !vecNear(got, w) {
+				t.Errorf("%v: Transform(%v) = %v, want %v", name, v, got, w)
+			}
+
+			if got, w := m.TransformDir(v), mgl32.TransformNormal(p, want); !vecNear(got, w) {
+				t.Errorf("%v: TransformDir(%v) = %v, want %v", name, v, got, w)
+			}
+
+			in := v
+			m.TransformIn(&in)
+			if got := m.Transform(v); in != got {
+				t.Errorf("%v: TransformIn(%v) = %v, want %v", name, v, in, got)
+			}
+		}
+	}
+}
+
+func TestMatInPlace(t *testing.T) {
+	for name, want := range testMats {
+		pos := m3(1, -2, 3)
+		scale := m3(2, 0.5, -1)
+
+		got := Mat(want)
+		got.PosIn(Vec{pos[0], pos[1], pos[2]})
+		if w := want.Mul4(mgl32.Translate3D(pos[0], pos[1], pos[2])); !matNear(got, w) {
+			t.Errorf("%v: PosIn = %v, want %v", name, got, Mat(w))
+		}
+
+		got = Mat(want)
+		got.ScaleIn(Vec{scale[0], scale[1], scale[2]})
+		if w := want.Mul4(mgl32.Scale3D(scale[0], scale[1], scale[2])); !matNear(got, w) {
+			t.Errorf("%v: ScaleIn = %v, want %v", name, got, Mat(w))
+		}
+	}
+}
+
+func TestMatDecompose(t *testing.T) {
+	tests := []struct {
+		pos   mgl32.Vec3
+		axis  mgl32.Vec3
+		rad   float32
+		scale mgl32.Vec3
+	}{
+		{m3(0, 0, 0), m3(0, 0, 1), 0, m3(1, 1, 1)},
+		{m3(1, 2, 3), m3(0, 0, 1), 0.5, m3(1, 1, 1)},
+		{m3(-4, 0, 2), m3(1, 1, 0), 1.3, m3(2, 3, 4)},
+		{m3(0, 5, 0), m3(0.2, -1, 0.4), -2.1, m3(0.5, 0.5, 0.5)},
+		{m3(1, 1, 1), m3(1, 0, 0), 0.8, m3(-2, 1, 3)},
+	}
+
+	for _, tt := range tests {
+		want := mgl32.Translate3D(tt.pos[0], tt.pos[1], tt.pos[2]).
+			Mul4(mgl32.HomogRotate3D(tt.rad, tt.axis.Normalize())).
+			Mul4(mgl32.Scale3D(tt.scale[0], tt.scale[1], tt.scale[2]))
+
+		pos, rot, scale := Mat(want).Decompose()
+
+		if !vecNear(pos, tt.pos) {
+			t.Errorf("Decompose(%v) pos = %v, want %v", Mat(want), pos, tt.pos)
+		}
+
+		if got := Ident().Pos(pos).Mul(rot.Mat()).Scale(scale); !matNear(got, want) {
+			t.Errorf("Decompose(%v) recomposes to %v", Mat(want), got)
+		}
+	}
+}
+
+func m3(x, y, z float32) mgl32.Vec3 {
+	return mgl32.Vec3{x, y, z}
+}
@@ -0,0 +1,218 @@
+package blit
+
+import "fmt"
+
+// Drawable is anything that can be drawn with a given world transform
+type Drawable interface {
+	DrawMat(m Mat)
+}
+
+// Node is a scene graph node. World transforms are cached and only recomputed
+// after the node or one of its ancestors has been marked dirty.
+//
+// The local transform is held in O. Its Parent is ignored, as the hierarchy is
+// described by the nodes themselves. Call Dirty after modifying O directly.
+type Node struct {
+	O *Orienter
+
+	Drawable Drawable // drawn with the world transform of the node, may be nil
+	Visible  bool     // visibility of the node and its descendants
+
+	parent   *Node
+	children []*Node
+
+	world Mat
+	dirty bool
+}
+
+// NewNode creates a new visible node drawing the given drawable (may be nil)
+func NewNode(d Drawable) *Node {
+	return &Node{
+		O:        NewOrienter(),
+		Drawable: d,
+		Visible:  true,
+		dirty:    true,
+	}
+}
+
+// Parent returns the parent of the node
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Children returns the children of the node in draw order
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// Add appends children to the node, detaching them from their previous parents.
+// The local transforms of the children are kept.
+func (n *Node) Add(children ...*Node) error {
+	for _, c := range children {
+		for p := n; p != nil; p = p.parent {
+			if p == c {
+				return fmt.Errorf("node cannot be added to itself or a descendant")
+			}
+		}
+
+		c.Detach()
+		c.parent = n
+		n.children = append(n.children, c)
+		c.Dirty()
+	}
+
+	return nil
+}
+
+// Remove removes a child from the node and reports whether it was found
+func (n *Node) Remove(child *Node) bool {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			c.parent = nil
+			c.Dirty()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Detach removes the node from its parent
+func (n *Node) Detach() {
+	if n.parent != nil {
+		n.parent.Remove(n)
+	}
+}
+
+// SetParent moves the node to a new parent (or the root if nil) while
+// preserving its world transform
+func (n *Node) SetParent(parent *Node) error {
+	world := n.World()
+
+	if parent == nil {
+		n.Detach()
+	} else if err := parent.Add(n); err != nil {
+		return err
+	}
+
+	local := world
+	if parent != nil {
+		local = parent.World().Inv().Mul(world)
+	}
+
+	n.O.setLocal(local)
+	n.Dirty()
+
+	return nil
+}
+
+// Dirty marks the world transform of the node and its descendants as stale
+func (n *Node) Dirty() {
+	if n.dirty {
+		// descendants of a dirty node are always dirty
+		return
+	}
+
+	n.dirty = true
+	for _, c := range n.children {
+		c.Dirty()
+	}
+}
+
+// SetPos sets the local position
+func (n *Node) SetPos(v Vec) {
+	n.O.Vec = v
+	n.Dirty()
+}
+
+// SetRot sets the local clockwise rotation along the z axis
+func (n *Node) SetRot(r float32) {
+	n.O.R = r
+	n.Dirty()
+}
+
+// SetScale sets the local scale
+func (n *Node) SetScale(s Vec) {
+	n.O.S = s
+	n.Dirty()
+}
+
+// SetPivot sets the local pivot
+func (n *Node) SetPivot(p Vec) {
+	n.O.Pivot = p
+	n.Dirty()
+}
+
+// Local returns the transform matrix relative to the parent
+func (n *Node) Local() Mat {
+	return n.O.Local()
+}
+
+// World returns the cached world transform matrix, recomputing it if dirty
+func (n *Node) World() Mat {
+	if n.dirty {
+		n.world = n.O.Local()
+		if n.parent != nil {
+			n.world = n.parent.World().Mul(n.world)
+		}
+
+		n.dirty = false
+	}
+
+	return n.world
+}
+
+// ToWorld converts a point in local space to world space
+func (n *Node) ToWorld(p Vec) Vec {
+	return n.World().Transform(p)
+}
+
+// ToLocal converts a point in world space to local space
+func (n *Node) ToLocal(p Vec) Vec {
+	return n.World().Inv().Transform(p)
+}
+
+// Shown reports whether the node and all of its ancestors are visible
+func (n *Node) Shown() bool {
+	for p := n; p != nil; p = p.parent {
+		if !p.Visible {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Walk traverses the node and its descendants depth first, parents before
+// children. Returning false from fn skips the children of that node.
+func (n *Node) Walk(fn func(n *Node, depth int) bool) {
+	n.walk(fn, 0)
+}
+
+func (n *Node) walk(fn func(n *Node, depth int) bool, depth int) {
+	if !fn(n, depth) {
+		return
+	}
+
+	for _, c := range n.children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// Draw draws the drawables of the node and its visible descendants in
+// traversal order
+func (n *Node) Draw() {
+	n.Walk(func(n *Node, _ int) bool {
+		if !n.Visible {
+			return false
+		}
+
+		if n.Drawable != nil {
+			n.Drawable.DrawMat(n.World())
+		}
+
+		return true
+	})
+}
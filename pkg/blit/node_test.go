@@ -0,0 +1,237 @@
+package blit
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testNodes returns a root with a child and a grandchild, each translated,
+// rotated and scaled
+func testNodes() (root, child, grandchild *Node) {
+	root = NewNode(nil)
+	root.SetPos(Vec{10, 5, 0})
+	root.SetRot(0.5)
+	root.SetScale(Vec{2, 2, 2, 1})
+
+	child = NewNode(nil)
+	child.SetPos(Vec{3, -1, 0})
+	child.SetRot(-1.2)
+
+	grandchild = NewNode(nil)
+	grandchild.SetPos(Vec{1, 2, 0})
+	grandchild.SetScale(Vec{0.5, 3, 1, 1})
+	grandchild.SetPivot(Vec{1, 1, 0})
+
+	if err := root.Add(child); err != nil {
+		panic(err)
+	}
+	if err := child.Add(grandchild); err != nil {
+		panic(err)
+	}
+
+	return
+}
+
+// uncachedWorld composes the local transforms up the hierarchy
+func uncachedWorld(n *Node) mgl32.Mat4 {
+	m := n.Local()
+	for p := n.parent; p != nil; p = p.parent {
+		m = p.Local().Mul(m)
+	}
+
+	return mgl32.Mat4(m)
+}
+
+func TestNodeWorld(t *testing.T) {
+	root, child, grandchild := testNodes()
+	nodes := map[string]*Node{"root": root, "child": child, "grandchild": grandchild}
+
+	check := func(step string) {
+		t.Helper()
+
+		for name, n := range nodes {
+			if got, want := n.World(), uncachedWorld(n); !matNear(got, want) {
+				t.Errorf("%v: %v World() = %v, want %v", step, name, got, Mat(want))
+			}
+		}
+	}
+
+	check("initial")
+
+	root.SetPos(Vec{-4, 2, 1})
+	check("root moved")
+
+	// only the child is recomputed, the grandchild stays dirty until the root
+	// changes again
+	child.SetRot(2)
+	child.World()
+	root.SetRot(-0.3)
+	check("child rotated, then root rotated")
+
+	root.O.S = Vec{1, 3, 1, 1}
+	root.Dirty()
+	check("root scaled directly")
+
+	grandchild.SetPivot(Vec{-2, 0, 0})
+	check("grandchild pivot")
+
+	child.Remove(grandchild)
+	if got, want := grandchild.World(), grandchild.Local(); got != want {
+		t.Errorf("removed grandchild World() = %v, want its local %v", got, want)
+	}
+
+	root.Add(grandchild)
+	check("grandchild moved to root")
+}
+
+func TestNodeWorldCached(t *testing.T) {
+	root, child, grandchild := testNodes()
+	want := grandchild.World()
+
+	// changing O without Dirty is not picked up, the cache is used
+	root.O.Vec = Vec{100, 100, 0}
+	if got := grandchild.World(); got != want {
+		t.Errorf("World() = %v without Dirty, want the cached %v", got, want)
+	}
+
+	root.Dirty()
+	if !child.dirty || !grandchild.dirty {
+		t.Errorf("Dirty did not mark the descendants")
+	}
+
+	if got := grandchild.World(); got == want {
+		t.Errorf("World() = %v after Dirty, want it recomputed", got)
+	}
+}
+
+func TestNodeAddCycle(t *testing.T) {
+	root, child, grandchild := testNodes()
+
+	tests := []struct {
+		name         string
+		parent, node *Node
+	}{
+		{"itself", root, root},
+		{"parent", child, root},
+		{"grandparent", grandchild, root},
+		{"child", grandchild, child},
+	}
+
+	for _, tt := range tests {
+		if err := tt.parent.Add(tt.node); err == nil {
+			t.Errorf("adding a node to its %v succeeded", tt.name)
+		}
+
+		if err := tt.node.SetParent(tt.parent); err == nil {
+			t.Errorf("parenting a node to its %v succeeded", tt.name)
+		}
+	}
+
+	if root.Parent() != nil || child.Parent() != root || grandchild.Parent() != child {
+		t.Errorf("a failed Add changed the hierarchy")
+	}
+
+	if len(root.Children()) != 1 || len(child.Children()) != 1 || len(grandchild.Children()) != 0 {
+		t.Errorf("a failed Add changed the children")
+	}
+}
+
+func TestNodeAdd(t *testing.T) {
+	root, child, grandchild := testNodes()
+	local := grandchild.Local()
+
+	// Add moves the node and keeps its local transform
+	if err := root.Add(grandchild); err != nil {
+		t.Fatal(err)
+	}
+
+	if grandchild.Parent() != root || len(child.Children()) != 0 || len(root.Children()) != 2 {
+		t.Errorf("Add did not move the node from its previous parent")
+	}
+
+	if got := grandchild.Local(); got != local {
+		t.Errorf("Add changed the local transform from %v to %v", local, got)
+	}
+}
+
+func TestNodeSetParent(t *testing.T) {
+	root, child, grandchild := testNodes()
+
+	other := NewNode(nil)
+	other.SetPos(Vec{-7, 3, 0})
+	other.SetRot(2.2)
+	other.SetScale(Vec{0.5, 0.5, 0.5, 1})
+
+	steps := []struct {
+		name   string
+		parent *Node
+	}{
+		{"root", root},
+		{"other", other},
+		{"none", nil},
+		{"child", child},
+	}
+
+	for _, s := range steps {
+		want := grandchild.World()
+
+		if err := grandchild.SetParent(s.parent); err != nil {
+			t.Fatalf("SetParent(%v): %v", s.name, err)
+		}
+
+		if grandchild.Parent() != s.parent {
+			t.Errorf("SetParent(%v) did not set the parent", s.name)
+		}
+
+		if got := grandchild.World(); !matNear(got, mgl32.Mat4(want)) {
+			t.Errorf("SetParent(%v) changed World() from %v to %v", s.name, want, got)
+		}
+
+		if got, w := grandchild.World(), uncachedWorld(grandchild); !matNear(got, w) {
+			t.Errorf("SetParent(%v) left a stale World() %v, want %v", s.name, got, Mat(w))
+		}
+	}
+}
+
+// drawRecorder records the nodes drawn
+type drawRecorder struct {
+	name  string
+	drawn *[]string
+}
+
+func (d drawRecorder) DrawMat(m Mat) {
+	*d.drawn = append(*d.drawn, d.name)
+}
+
+func TestNodeDraw(t *testing.T) {
+	var drawn []string
+	node := func(name string) *Node {
+		return NewNode(drawRecorder{name, &drawn})
+	}
+
+	root := node("root")
+	a, b, c := node("a"), node("b"), node("c")
+	a1, b1 := node("a1"), node("b1")
+
+	root.Add(a, b, c)
+	a.Add(a1)
+	b.Add(b1)
+	b.Visible = false
+
+	root.Draw()
+
+	want := []string{"root", "a", "a1", "c"}
+	if len(drawn) != len(want) {
+		t.Fatalf("drew %v, want %v", drawn, want)
+	}
+	for i := range want {
+		if drawn[i] != want[i] {
+			t.Fatalf("drew %v, want %v", drawn, want)
+		}
+	}
+
+	if b1.Shown() || !a1.Shown() {
+		t.Errorf("Shown() = %v for a hidden parent, %v for a visible one", b1.Shown(), a1.Shown())
+	}
+}
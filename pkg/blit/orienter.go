@@ -1,21 +1,97 @@
 package blit
 
+import (
+	"fmt"
+	"math"
+)
+
+// Orienter is a node in a transform hierarchy. The position, rotation, scale
+// and pivot are local to the parent, if any.
 type Orienter struct {
 	Parent *Orienter
-	Vec
-	R float32
+	Vec            // position
+	Q      Quat    // 3D orientation (the zero quaternion is treated as no rotation)
+	R      float32 // clockwise 2D rotation along the z axis, applied after Q
+	S      Vec     // scale (the zero vector is treated as a scale of 1)
+	Pivot  Vec     // local point that is positioned, rotated and scaled around
+}
+
+// NewOrienter creates a new orienter with a scale of 1
+func NewOrienter() *Orienter {
+	return &Orienter{
+		S: Vec{1, 1, 1, 1},
+	}
+}
+
+// scale returns the local scale, treating the zero vector as a scale of 1
+func (o *Orienter) scale() Vec {
+	if o.S == (Vec{}) {
+		return Vec{1, 1, 1, 1}
+	}
+
+	return o.S
+}
+
+// quat returns the local 3D orientation, treating the zero quaternion as no
+// rotation
+func (o *Orienter) quat() Quat {
+	if o.Q == (Quat{}) {
+		return QuatIdent()
+	}
+
+	return o.Q
+}
+
+// Orientation gets the local 3D orientation, combining Q and R
+func (o *Orienter) Orientation() Quat {
+	return o.quat().Mul(QuatAxisAngle(Vec{0, 0, 1}, -o.R))
 }
 
-// Pos gets the position vector relative to the parent
+// WorldOrientation gets the world 3D orientation
+func (o *Orienter) WorldOrientation() Quat {
+	if o.Parent != nil {
+		return o.Parent.WorldOrientation().Mul(o.Orientation())
+	}
+
+	return o.Orientation()
+}
+
+// Rotate applies a rotation relative to the current local orientation
+func (o *Orienter) Rotate(q Quat) {
+	o.Q = o.quat().Mul(q).Nrm()
+}
+
+// Local calculates the transform matrix relative to the parent
+func (o *Orienter) Local() Mat {
+	m := Ident()
+	m = m.Pos(o.Vec)
+	m = m.Mul(o.quat().Mat())
+	m = m.Rot(o.R)
+	m = m.Scale(o.scale())
+	m = m.Pos(o.Pivot.Inv())
+
+	return m
+}
+
+// Mat calculates the world transform matrix by composing the parent chain
+func (o *Orienter) Mat() Mat {
+	if o.Parent != nil {
+		return o.Parent.Mat().Mul(o.Local())
+	}
+
+	return o.Local()
+}
+
+// Pos gets the world position
 func (o *Orienter) Pos() Vec {
 	if o.Parent != nil {
-		return o.Parent.Pos().Add(o.Vec)
+		return o.Parent.ToWorld(o.Vec)
 	}
 
 	return o.Vec
 }
 
-// Rot gets the rotation relative to the parent
+// Rot gets the world rotation along the z axis, ignoring Q
 func (o *Orienter) Rot() float32 {
 	if o.Parent != nil {
 		return o.Parent.Rot() + o.R
@@ -24,12 +100,67 @@ func (o *Orienter) Rot() float32 {
 	return o.R
 }
 
-// Mat calculates the translation matrix relative to the parent
-func (o *Orienter) Mat() Mat {
-	// NOTE: both Pos() and Rot() are relative to the parent already
-	m := Ident()
-	m = m.Pos(o.Pos())
-	m = m.Rot(o.Rot())
+// Scale gets the world scale, the lengths of the axes of the world transform
+// (negated along x if it is mirrored). Rotated parents with a non-uniform scale
+// shear their children; the shear is not part of the scale.
+func (o *Orienter) Scale() Vec {
+	if o.Parent != nil {
+		_, _, s := o.Mat().Decompose()
+		return s
+	}
 
-	return m
+	return o.scale()
+}
+
+// ToWorld converts a point in local space to world space
+func (o *Orienter) ToWorld(p Vec) Vec {
+	return o.Mat().Transform(p)
+}
+
+// ToLocal converts a point in world space to local space
+func (o *Orienter) ToLocal(p Vec) Vec {
+	return o.Mat().Inv().Transform(p)
+}
+
+// SetParent changes the parent while preserving the world transform. Skew
+// introduced by non-uniformly scaled, rotated parents cannot be represented and
+// is discarded.
+func (o *Orienter) SetParent(parent *Orienter) error {
+	for p := parent; p != nil; p = p.Parent {
+		if p == o {
+			return fmt.Errorf("orienter cannot be parented to itself or a descendant")
+		}
+	}
+
+	world := o.Mat()
+
+	o.Parent = parent
+
+	local := world
+	if parent != nil {
+		local = parent.Mat().Inv().Mul(world)
+	}
+
+	o.setLocal(local)
+
+	return nil
+}
+
+// setLocal sets the local position, orientation and scale from a local
+// transform matrix, keeping the pivot. Rotations purely along the z axis are
+// stored in R, anything else in Q.
+func (o *Orienter) setLocal(m Mat) {
+	_, q, s := m.Decompose()
+
+	o.S = s
+	if math.Abs(float64(q[0])) < 1e-6 && math.Abs(float64(q[1])) < 1e-6 {
+		o.Q = Quat{}
+		o.R = -2 * float32(math.Atan2(float64(q[2]), float64(q[3])))
+	} else {
+		o.Q = q
+		o.R = 0
+	}
+
+	// the pivot is mapped onto the position by the local transform
+	o.Vec = m.Transform(o.Pivot)
 }
@@ -0,0 +1,179 @@
+package blit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testOrienters returns a chain of orienters with translations, 2D and 3D
+// rotations, uniform scales and pivots
+func testOrienters() (root, parent, child *Orienter) {
+	root = NewOrienter()
+	root.Vec = Vec{10, -4, 2}
+	root.R = 0.7
+	root.S = Vec{2, 2, 2, 1}
+
+	parent = NewOrienter()
+	parent.Parent = root
+	parent.Vec = Vec{-3, 5, 1}
+	parent.Q = QuatAxisAngle(Vec{1, 2, 3}, 0.9)
+	parent.S = Vec{0.5, 0.5, 0.5, 1}
+	parent.Pivot = Vec{1, 1, 0}
+
+	child = NewOrienter()
+	child.Vec = Vec{4, 2, -1}
+	child.R = -1.3
+	child.S = Vec{3, 0.5, 2, 1}
+	child.Pivot = Vec{0.5, -0.5, 0}
+
+	return
+}
+
+func TestOrienterSetParent(t *testing.T) {
+	root, parent, child := testOrienters()
+
+	// each step moves the child to a different parent and must keep its world
+	// transform
+	steps := []struct {
+		name   string
+		parent *Orienter
+	}{
+		{"root", root},
+		{"parent", parent},
+		{"none", nil},
+		{"parent again", parent},
+	}
+
+	for _, s := range steps {
+		want := child.Mat()
+		pos := child.Pos()
+
+		if err := child.SetParent(s.parent); err != nil {
+			t.Fatalf("SetParent(%v): %v", s.name, err)
+		}
+
+		if child.Parent != s.parent {
+			t.Errorf("SetParent(%v) did not set the parent", s.name)
+		}
+
+		if got := child.Mat(); !matNear(got, mgl32.Mat4(want)) {
+			t.Errorf("SetParent(%v) changed Mat() from %v to %v", s.name, want, got)
+		}
+
+		if got := child.Pos(); !vecNear(got, m3(pos[0], pos[1], pos[2])) {
+			t.Errorf("SetParent(%v) changed Pos() from %v to %v", s.name, pos, got)
+		}
+
+		if child.Pivot != (Vec{0.5, -0.5, 0}) {
+			t.Errorf("SetParent(%v) changed the pivot to %v", s.name, child.Pivot)
+		}
+	}
+}
+
+func TestOrienterSetParentCycle(t *testing.T) {
+	root, parent, child := testOrienters()
+	child.Parent = parent
+
+	for _, p := range []*Orienter{root, parent, child} {
+		if err := root.SetParent(p); err == nil {
+			t.Errorf("SetParent to itself or a descendant succeeded")
+		}
+	}
+
+	if root.Parent != nil {
+		t.Errorf("failed SetParent changed the parent to %v", root.Parent)
+	}
+}
+
+func TestOrienterToWorld(t *testing.T) {
+	_, parent, child := testOrienters()
+	child.Parent = parent
+
+	points := []Vec{{0, 0, 0, 1}, {1, 2, 3, 1}, {-4, 0.5, -2, 1}}
+
+	for _, o := range []*Orienter{parent, child} {
+		m := mgl32.Mat4(o.Mat())
+
+		for _, p := range points {
+			want := mgl32.TransformCoordinate(m3(p[0], p[1], p[2]), m)
+
+			w := o.ToWorld(p)
+			if !vecNear(w, want) {
+				t.Errorf("ToWorld(%v) = %v, want %v", p, w, want)
+			}
+
+			if got := o.ToLocal(w); !vecNear(got, m3(p[0], p[1], p[2])) {
+				t.Errorf("ToLocal(ToWorld(%v)) = %v", p, got)
+			}
+		}
+
+		// the pivot is placed at the position
+		if got := o.ToWorld(o.Pivot); !vecNear(got, m3(o.Pos()[0], o.Pos()[1], o.Pos()[2])) {
+			t.Errorf("ToWorld(Pivot) = %v, want %v", got, o.Pos())
+		}
+	}
+}
+
+func TestOrienterScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent *Orienter
+		child  *Orienter
+		want   mgl32.Vec3
+	}{
+		{
+			"no parent",
+			nil,
+			&Orienter{S: Vec{2, 3, 4, 1}, R: 0.5},
+			m3(2, 3, 4),
+		},
+		{
+			"zero scale",
+			nil,
+			&Orienter{},
+			m3(1, 1, 1),
+		},
+		{
+			"unrotated",
+			&Orienter{S: Vec{2, 3, 1, 1}},
+			&Orienter{S: Vec{0.5, 2, 3, 1}},
+			m3(1, 6, 3),
+		},
+		{
+			"rotated child",
+			// the child's x axis lies along the parent's y axis
+			&Orienter{S: Vec{2, 3, 1, 1}, Vec: Vec{5, 5, 0}},
+			&Orienter{S: Vec{1, 1, 1, 1}, R: -math.Pi / 2},
+			m3(3, 2, 1),
+		},
+		{
+			"rotated parent",
+			&Orienter{S: Vec{2, 3, 1, 1}, R: 1.1},
+			&Orienter{S: Vec{0.5, 2, 3, 1}},
+			m3(1, 6, 3),
+		},
+		{
+			"3D rotated child",
+			// the child's z axis lies along the parent's x axis
+			&Orienter{S: Vec{4, 1, 2, 1}},
+			&Orienter{S: Vec{1, 1, 1, 1}, Q: QuatAxisAngle(Vec{0, 1, 0}, math.Pi/2)},
+			m3(2, 1, 4),
+		},
+		{
+			"mirrored",
+			&Orienter{S: Vec{-2, 1, 1, 1}},
+			&Orienter{S: Vec{1, 3, 1, 1}},
+			m3(-2, 3, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		tt.child.Parent = tt.parent
+
+		if got := tt.child.Scale(); !vecNear(got, tt.want) {
+			t.Errorf("%v: Scale() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
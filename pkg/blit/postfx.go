@@ -0,0 +1,322 @@
+package blit
+
+import (
+	"embed"
+	"time"
+
+	"github.com/octalide/blit/pkg/bgl"
+	"github.com/octalide/wisp/pkg/wisp"
+)
+
+var (
+	//go:embed shaders
+	shaders embed.FS
+)
+
+// PostProgram compiles a post-processing program from the full screen vertex
+// shader and a fragment shader source. The fragment shader receives the
+// texture coordinates as "in vec2 uv".
+func PostProgram(frag string) (*bgl.Program, error) {
+	vert, err := shaders.ReadFile("shaders/post.vert")
+	if err != nil {
+		return nil, err
+	}
+
+	return bgl.NewProgram([]*bgl.Shader{
+		bgl.NewShader(string(vert)+"\x00", bgl.VertShader),
+		bgl.NewShader(frag+"\x00", bgl.FragShader),
+	})
+}
+
+// postProgram compiles one of the embedded post-processing fragment shaders
+func postProgram(name string) (*bgl.Program, error) {
+	return bgl.LoadProgramFS(shaders, "shaders/post.vert", "shaders/"+name)
+}
+
+// Pass is a single full screen draw of an Effect. Besides its own uniforms
+// and textures, each pass is given the following uniforms if it declares them:
+//
+//	sampler2D src   output of the previous pass
+//	sampler2D orig  input of the effect, i.e. the output of the previous effect
+//	vec2 texel      size of one texel in uv units
+//	float time      seconds since the chain was created
+type Pass struct {
+	Program  *bgl.Program
+	Uniforms map[string]interface{}  // set with Program.SetUniform before drawing
+	Textures map[string]*bgl.Texture // additional samplers
+}
+
+// NewPass creates a pass drawing with the given program
+func NewPass(p *bgl.Program) *Pass {
+	return &Pass{
+		Program:  p,
+		Uniforms: map[string]interface{}{},
+		Textures: map[string]*bgl.Texture{},
+	}
+}
+
+// Effect is a named, toggleable list of passes
+type Effect struct {
+	Name    string
+	Enabled bool
+	Passes  []*Pass
+}
+
+// NewEffect creates an enabled effect
+func NewEffect(name string, passes ...*Pass) *Effect {
+	return &Effect{
+		Name:    name,
+		Enabled: true,
+		Passes:  passes,
+	}
+}
+
+// Set sets a uniform in every pass whose program declares it
+func (e *Effect) Set(name string, value interface{}) {
+	for _, p := range e.Passes {
+		if _, ok := p.Program.Uniform(name); ok {
+			p.Uniforms[name] = value
+		}
+	}
+}
+
+// PostFX is a post-processing chain. The scene is drawn into an offscreen
+// target between Begin and End; End then runs the passes of all enabled
+// effects in order, ping-ponging between intermediate targets, and draws the
+// result into the framebuffer that was bound before Begin.
+type PostFX struct {
+	Effects []*Effect
+
+	width, height int
+
+	// targets[0] is the scene target and the only one with a depth buffer
+	targets  [3]*bgl.Framebuffer
+	textures [3]*bgl.Texture
+	depth    *bgl.Renderbuffer
+
+	copy  *bgl.Program
+	vbo   *bgl.VBO
+	vao   *bgl.VAO
+	start time.Time
+}
+
+// NewPostFX creates a post-processing chain with targets of the given size
+func NewPostFX(width, height int) (*PostFX, error) {
+	cp, err := postProgram("copy.frag")
+	if err != nil {
+		return nil, err
+	}
+
+	fx := &PostFX{
+		width:  width,
+		height: height,
+		copy:   cp,
+		start:  time.Now(),
+	}
+
+	// half float targets keep values above 1 for bloom
+	for i := range fx.targets {
+		fx.textures[i] = bgl.NewTextureFormat(width, height, bgl.FormatRGBA16F, bgl.Linear)
+		fx.targets[i] = bgl.NewFramebuffer(width, height)
+		fx.targets[i].AttachTexture(bgl.ColorAttachment(0), fx.textures[i])
+	}
+
+	fx.depth = bgl.NewRenderbuffer(width, height, bgl.FormatDepth24, 0)
+	fx.targets[0].AttachRenderbuffer(bgl.DepthAttachment, fx.depth)
+
+	for _, t := range fx.targets {
+		if err := t.Check(); err != nil {
+			return nil, err
+		}
+	}
+
+	// a single triangle covering the screen
+	fx.vbo = bgl.NewVBO()
+	fx.vbo.SetData([]float32{-1, -1, 3, -1, -1, 3})
+	fx.vao = bgl.NewVAO(bgl.AttrFormat{{Type: bgl.Vec2f, Name: "pos", Loc: 0}})
+	fx.vbo.Unbind()
+
+	return fx, nil
+}
+
+// Add appends effects to the chain
+func (fx *PostFX) Add(effects ...*Effect) {
+	fx.Effects = append(fx.Effects, effects...)
+}
+
+// Effect returns the effect with the given name, nil if there is none
+func (fx *PostFX) Effect(name string) *Effect {
+	for _, e := range fx.Effects {
+		if e.Name == name {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// Resize resizes the targets, e.g. when the window is resized
+func (fx *PostFX) Resize(width, height int) {
+	if width <= 0 || height <= 0 || (width == fx.width && height == fx.height) {
+		return
+	}
+
+	fx.width = width
+	fx.height = height
+
+	for _, t := range fx.targets {
+		t.Resize(width, height)
+	}
+}
+
+// Listen adds a wisp handler resizing the chain with the window
+func (fx *PostFX) Listen() *wisp.Handler {
+	h := &wisp.Handler{
+		Callback: func(e *wisp.Event) bool {
+			size := e.Data.(Vec)
+			fx.Resize(int(size.X()), int(size.Y()))
+
+			return false
+		},
+		Tags:     []string{"core.window.resize"},
+		Blocking: false,
+	}
+
+	wisp.AddHandler(h)
+
+	return h
+}
+
+// Begin redirects drawing into the scene target and clears it
+func (fx *PostFX) Begin() {
+	fx.targets[0].Bind()
+	bgl.Clear()
+}
+
+// End stops drawing into the scene target and runs the chain
+func (fx *PostFX) End() {
+	fx.targets[0].Unbind()
+
+	depthTest := bgl.DepthTest()
+	bgl.DisableDepthTest()
+
+	for _, s := range planPostFX(fx.Effects, NewPass(fx.copy)) {
+		if s.dst < 0 {
+			fx.draw(s.pass, s.src, s.orig)
+			continue
+		}
+
+		fx.targets[s.dst].Bind()
+		fx.draw(s.pass, s.src, s.orig)
+		fx.targets[s.dst].Unbind()
+	}
+
+	if depthTest {
+		bgl.EnableDepthTest()
+	}
+}
+
+// postStep is a pass of a post-processing chain and the targets it uses
+type postStep struct {
+	pass      *Pass
+	src, orig int // targets sampled as "src" and "orig"
+	dst       int // target drawn into, -1 for the output
+}
+
+// planPostFX orders the passes of the enabled effects and assigns their
+// targets, starting from the scene in target 0. Each pass draws into a target
+// it does not read and the last one into the output; without any passes, the
+// scene is drawn into the output with copy.
+func planPostFX(effects []*Effect, copy *Pass) []postStep {
+	type pass struct {
+		pass  *Pass
+		first bool // first pass of its effect
+	}
+
+	var passes []pass
+	for _, e := range effects {
+		if !e.Enabled {
+			continue
+		}
+
+		for i, p := range e.Passes {
+			passes = append(passes, pass{p, i == 0})
+		}
+	}
+
+	if len(passes) == 0 {
+		passes = append(passes, pass{copy, true})
+	}
+
+	steps := make([]postStep, len(passes))
+
+	src, orig := 0, 0
+	for i, p := range passes {
+		if p.first {
+			orig = src
+		}
+
+		steps[i] = postStep{pass: p.pass, src: src, orig: orig, dst: -1}
+
+		// the last pass draws into the output
+		if i == len(passes)-1 {
+			break
+		}
+
+		// any target that is neither read as src nor as orig
+		dst := 0
+		for dst == src || dst == orig {
+			dst++
+		}
+
+		steps[i].dst = dst
+		src = dst
+	}
+
+	return steps
+}
+
+// draw draws a pass reading from the given targets
+func (fx *PostFX) draw(pass *Pass, src, orig int) {
+	p := pass.Program
+
+	fx.textures[src].BindUnit(0)
+	fx.textures[orig].BindUnit(1)
+
+	// uniforms a pass does not declare are skipped
+	p.SetSampler("src", 0)
+	p.SetSampler("orig", 1)
+	p.SetVec2f("texel", [2]float32{1 / float32(fx.width), 1 / float32(fx.height)})
+	p.SetFloat("time", float32(time.Since(fx.start).Seconds()))
+
+	unit := uint32(2)
+	for name, t := range pass.Textures {
+		t.BindUnit(unit)
+		p.SetSampler(name, int32(unit))
+		unit++
+	}
+
+	for name, v := range pass.Uniforms {
+		p.SetUniform(name, v)
+	}
+
+	p.Bind()
+	fx.vao.Bind()
+	fx.vbo.DrawRange(0, 3)
+	fx.vao.Unbind()
+	p.Unbind()
+}
+
+// Delete deletes the targets of the chain. The programs of its effects are
+// not deleted.
+func (fx *PostFX) Delete() {
+	for i := range fx.targets {
+		fx.targets[i].Delete()
+		fx.textures[i].Delete()
+	}
+
+	fx.depth.Delete()
+	fx.copy.Delete()
+	fx.vbo.Delete()
+	fx.vao.Delete()
+}
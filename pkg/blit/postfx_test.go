@@ -0,0 +1,100 @@
+package blit
+
+import "testing"
+
+func TestPlanPostFX(t *testing.T) {
+	cp := &Pass{}
+	p := make([]*Pass, 6)
+	for i := range p {
+		p[i] = &Pass{}
+	}
+
+	disabled := NewEffect("disabled", p[5])
+	disabled.Enabled = false
+
+	tests := []struct {
+		name    string
+		effects []*Effect
+		want    []postStep
+	}{
+		{
+			"no effects",
+			nil,
+			[]postStep{{cp, 0, 0, -1}},
+		},
+		{
+			"only disabled effects",
+			[]*Effect{disabled, NewEffect("empty")},
+			[]postStep{{cp, 0, 0, -1}},
+		},
+		{
+			"single effect",
+			[]*Effect{NewEffect("a", p[0])},
+			[]postStep{{p[0], 0, 0, -1}},
+		},
+		{
+			"single effects",
+			[]*Effect{NewEffect("a", p[0]), NewEffect("b", p[1]), NewEffect("c", p[2])},
+			[]postStep{
+				{p[0], 0, 0, 1},
+				{p[1], 1, 1, 0},
+				{p[2], 0, 0, -1},
+			},
+		},
+		{
+			"multi-pass effect",
+			[]*Effect{NewEffect("bloom", p[0], p[1], p[2])},
+			[]postStep{
+				{p[0], 0, 0, 1},
+				{p[1], 1, 0, 2},
+				{p[2], 2, 0, -1},
+			},
+		},
+		{
+			"multi-pass effect after another",
+			[]*Effect{NewEffect("a", p[0]), NewEffect("bloom", p[1], p[2], p[3]), NewEffect("b", p[4])},
+			[]postStep{
+				{p[0], 0, 0, 1},
+				{p[1], 1, 1, 0},
+				{p[2], 0, 1, 2},
+				{p[3], 2, 1, 0},
+				{p[4], 0, 0, -1},
+			},
+		},
+		{
+			"disabled and empty effects are skipped",
+			[]*Effect{NewEffect("a", p[0]), disabled, NewEffect("empty"), NewEffect("b", p[1], p[2])},
+			[]postStep{
+				{p[0], 0, 0, 1},
+				{p[1], 1, 1, 0},
+				{p[2], 0, 1, -1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := planPostFX(tt.effects, cp)
+		if len(got) != len(tt.want) {
+			t.Errorf("%v: got %v steps, want %v", tt.name, len(got), len(tt.want))
+			continue
+		}
+
+		for i, s := range got {
+			if s != tt.want[i] {
+				t.Errorf("%v: step %v = %+v, want %+v", tt.name, i, s, tt.want[i])
+			}
+
+			// a pass never draws into a target it reads
+			if s.dst == s.src || s.dst == s.orig {
+				t.Errorf("%v: step %v draws into target %v while reading it", tt.name, i, s.dst)
+			}
+
+			// targets index PostFX.targets
+			for _, x := range []int{s.src, s.orig, s.dst} {
+				if x < -1 || x >= len(PostFX{}.targets) {
+					t.Errorf("%v: step %v uses target %v", tt.name, i, x)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package blit
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quat is a quaternion {x, y, z, w} representing a 3D rotation
+type Quat [4]float32
+
+// QuatIdent returns the identity quaternion
+func QuatIdent() Quat {
+	return Quat{0, 0, 0, 1}
+}
+
+// QuatAxisAngle creates a quaternion rotating counter-clockwise around an axis
+func QuatAxisAngle(axis Vec, rad float32) Quat {
+	axis[3] = 0
+	axis = axis.Nrm()
+
+	s, c := math.Sincos(float64(rad / 2))
+	sin := float32(s)
+
+	return Quat{axis[0] * sin, axis[1] * sin, axis[2] * sin, float32(c)}
+}
+
+// QuatEuler creates a quaternion from euler angles, applied in the order roll
+// (z axis), pitch (x axis) then yaw (y axis)
+func QuatEuler(pitch, yaw, roll float32) Quat {
+	y := QuatAxisAngle(Vec{0, 1, 0}, yaw)
+	p := QuatAxisAngle(Vec{1, 0, 0}, pitch)
+	r := QuatAxisAngle(Vec{0, 0, 1}, roll)
+
+	return y.Mul(p).Mul(r)
+}
+
+// QuatLook creates a quaternion rotating the -z axis onto forward, keeping the
+// y axis as close to up as possible
+func QuatLook(forward, up Vec) Quat {
+	forward[3] = 0
+	up[3] = 0
+
+	z := forward.Nrm().Inv()
+	x := up.Crs(z).Nrm()
+	y := z.Crs(x)
+
+	return QuatMat(Mat{
+		x[0], x[1], x[2], 0,
+		y[0], y[1], y[2], 0,
+		z[0], z[1], z[2], 0,
+		0, 0, 0, 1,
+	})
+}
+
+// QuatMat creates a quaternion from the rotation in the upper 3x3 of a matrix.
+// The matrix must be orthonormal.
+func QuatMat(m Mat) Quat {
+	var q Quat
+
+	trace := m[0] + m[5] + m[10]
+	switch {
+	case trace > 0:
+		s := 0.5 / float32(math.Sqrt(float64(trace+1)))
+		q = Quat{(m[6] - m[9]) * s, (m[8] - m[2]) * s, (m[1] - m[4]) * s, 0.25 / s}
+	case m[0] > m[5] && m[0] > m[10]:
+		s := 2 * float32(math.Sqrt(float64(1+m[0]-m[5]-m[10])))
+		q = Quat{0.25 * s, (m[4] + m[1]) / s, (m[8] + m[2]) / s, (m[6] - m[9]) / s}
+	case m[5] > m[10]:
+		s := 2 * float32(math.Sqrt(float64(1+m[5]-m[0]-m[10])))
+		q = Quat{(m[4] + m[1]) / s, 0.25 * s, (m[9] + m[6]) / s, (m[8] - m[2]) / s}
+	default:
+		s := 2 * float32(math.Sqrt(float64(1+m[10]-m[0]-m[5])))
+		q = Quat{(m[8] + m[2]) / s, (m[9] + m[6]) / s, 0.25 * s, (m[1] - m[4]) / s}
+	}
+
+	return q.Nrm()
+}
+
+// X returns the x component of the quaternion
+func (q Quat) X() float32 {
+	return q[0]
+}
+
+// Y returns the y component of the quaternion
+func (q Quat) Y() float32 {
+	return q[1]
+}
+
+// Z returns the z component of the quaternion
+func (q Quat) Z() float32 {
+	return q[2]
+}
+
+// W returns the w component of the quaternion
+func (q Quat) W() float32 {
+	return q[3]
+}
+
+// Mul multiplies two quaternions. The result applies q2 first, then q.
+func (q Quat) Mul(q2 Quat) Quat {
+	return Quat{
+		q[3]*q2[0] + q[0]*q2[3] + q[1]*q2[2] - q[2]*q2[1],
+		q[3]*q2[1] - q[0]*q2[2] + q[1]*q2[3] + q[2]*q2[0],
+		q[3]*q2[2] + q[0]*q2[1] - q[1]*q2[0] + q[2]*q2[3],
+		q[3]*q2[3] - q[0]*q2[0] - q[1]*q2[1] - q[2]*q2[2],
+	}
+}
+
+// Conj returns the conjugate of the quaternion
+func (q Quat) Conj() Quat {
+	return Quat{-q[0], -q[1], -q[2], q[3]}
+}
+
+// Dot returns the dot product of two quaternions
+func (q Quat) Dot(q2 Quat) float32 {
+	return q[0]*q2[0] + q[1]*q2[1] + q[2]*q2[2] + q[3]*q2[3]
+}
+
+// Len returns the length of the quaternion
+func (q Quat) Len() float32 {
+	return float32(math.Sqrt(float64(q.Dot(q))))
+}
+
+// Nrm normalizes the quaternion. The zero quaternion normalizes to the
+// identity.
+func (q Quat) Nrm() Quat {
+	l := q.Len()
+	if l == 0 {
+		return QuatIdent()
+	}
+
+	return Quat{q[0] / l, q[1] / l, q[2] / l, q[3] / l}
+}
+
+// Inv returns the inverse of the quaternion
+func (q Quat) Inv() Quat {
+	d := q.Dot(q)
+	if d == 0 {
+		return QuatIdent()
+	}
+
+	c := q.Conj()
+
+	return Quat{c[0] / d, c[1] / d, c[2] / d, c[3] / d}
+}
+
+// Slerp spherically interpolates between two quaternions along the shortest
+// path
+func (q Quat) Slerp(q2 Quat, t float32) Quat {
+	d := q.Dot(q2)
+	if d < 0 {
+		q2 = Quat{-q2[0], -q2[1], -q2[2], -q2[3]}
+		d = -d
+	}
+
+	if d > 0.9995 {
+		// nearly parallel, fall back to a linear interpolation
+		return Quat{
+			q[0] + (q2[0]-q[0])*t,
+			q[1] + (q2[1]-q[1])*t,
+			q[2] + (q2[2]-q[2])*t,
+			q[3] + (q2[3]-q[3])*t,
+		}.Nrm()
+	}
+
+	theta := math.Acos(float64(d))
+	sin := math.Sin(theta)
+	a := float32(math.Sin((1-float64(t))*theta) / sin)
+	b := float32(math.Sin(float64(t)*theta) / sin)
+
+	return Quat{
+		q[0]*a + q2[0]*b,
+		q[1]*a + q2[1]*b,
+		q[2]*a + q2[2]*b,
+		q[3]*a + q2[3]*b,
+	}
+}
+
+// Rotate rotates a vector by the quaternion
+func (q Quat) Rotate(v Vec) Vec {
+	u := Vec{q[0], q[1], q[2]}
+	w := v[3]
+	v[3] = 0
+
+	t := u.Crs(v).Scl(2)
+	r := v.Add(t.Scl(q[3])).Add(u.Crs(t))
+	r[3] = w
+
+	return r
+}
+
+// Mat returns the rotation matrix of the quaternion
+func (q Quat) Mat() Mat {
+	x, y, z, w := q[0], q[1], q[2], q[3]
+
+	return Mat{
+		1 - 2*(y*y+z*z), 2 * (x*y + w*z), 2 * (x*z - w*y), 0,
+		2 * (x*y - w*z), 1 - 2*(x*x+z*z), 2 * (y*z + w*x), 0,
+		2 * (x*z + w*y), 2 * (y*z - w*x), 1 - 2*(x*x+y*y), 0,
+		0, 0, 0, 1,
+	}
+}
+
+// String returns a string representation of the quaternion, with each value
+// truncated to 3 decimal places
+func (q Quat) String() string {
+	return fmt.Sprintf("[%+.3f, %+.3f, %+.3f, %+.3f]", q[0], q[1], q[2], q[3])
+}
@@ -0,0 +1,259 @@
+package blit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// testAxes are the rotation axes of the quaternion tests, the principal axes
+// and a few arbitrary ones
+var testAxes = []mgl32.Vec3{
+	{1, 0, 0},
+	{0, 1, 0},
+	{0, 0, 1},
+	{1, 2, 3},
+	{-0.5, 1, -2},
+}
+
+// testQuats are rotations covering every branch of QuatMat: a positive trace
+// and a dominant x, y and z diagonal
+var testQuats = map[string]mgl32.Quat{
+	"ident":     mgl32.QuatIdent(),
+	"small":     mgl32.QuatRotate(0.4, mgl32.Vec3{1, 2, 3}.Normalize()),
+	"x":         mgl32.QuatRotate(3, mgl32.Vec3{1, 0, 0}),
+	"y":         mgl32.QuatRotate(3, mgl32.Vec3{0, 1, 0}),
+	"z":         mgl32.QuatRotate(3, mgl32.Vec3{0, 0, 1}),
+	"half turn": mgl32.QuatRotate(math.Pi, mgl32.Vec3{1, 1, 0}.Normalize()),
+	"arbitrary": mgl32.QuatRotate(-2.2, mgl32.Vec3{-0.5, 1, -2}.Normalize()),
+}
+
+func quat(q mgl32.Quat) Quat {
+	return Quat{q.V[0], q.V[1], q.V[2], q.W}
+}
+
+func quatNear(q Quat, want mgl32.Quat) bool {
+	w := quat(want)
+	for i := range q {
+		if math.Abs(float64(q[i]-w[i])) > mathEps {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameRot reports whether two quaternions represent the same rotation, q and
+// -q being equivalent
+func sameRot(q Quat, want mgl32.Quat) bool {
+	return quatNear(q, want) || quatNear(q, want.Scale(-1))
+}
+
+func TestQuatAxisAngle(t *testing.T) {
+	for _, axis := range testAxes {
+		for _, a := range testAngles {
+			want := mgl32.QuatRotate(a, axis.Normalize())
+
+			// the axis does not need to be normalized
+			q := QuatAxisAngle(Vec{axis[0] * 3, axis[1] * 3, axis[2] * 3, 1}, a)
+			if !quatNear(q, want) {
+				t.Errorf("QuatAxisAngle(%v, %v) = %v, want %v", axis, a, q, quat(want))
+			}
+
+			if got := q.Mat(); !matNear(got, want.Mat4()) {
+				t.Errorf("QuatAxisAngle(%v, %v).Mat() = %v, want %v", axis, a, got, Mat(want.Mat4()))
+			}
+
+			if got := q.Mat(); !matNear(got, mgl32.HomogRotate3D(a, axis.Normalize())) {
+				t.Errorf("QuatAxisAngle(%v, %v).Mat() does not match RotAxis", axis, a)
+			}
+		}
+	}
+}
+
+func TestQuatEuler(t *testing.T) {
+	tests := []struct {
+		pitch, yaw, roll float32
+	}{
+		{0, 0, 0},
+		{0.5, 0, 0},
+		{0, 0.5, 0},
+		{0, 0, 0.5},
+		{0.3, -1.2, 2},
+		{-1.5, math.Pi, 0.1},
+	}
+
+	for _, tt := range tests {
+		// roll first, then pitch, then yaw
+		want := mgl32.QuatRotate(tt.yaw, mgl32.Vec3{0, 1, 0}).
+			Mul(mgl32.QuatRotate(tt.pitch, mgl32.Vec3{1, 0, 0})).
+			Mul(mgl32.QuatRotate(tt.roll, mgl32.Vec3{0, 0, 1}))
+
+		if got := QuatEuler(tt.pitch, tt.yaw, tt.roll); !quatNear(got, want) {
+			t.Errorf("QuatEuler(%v, %v, %v) = %v, want %v", tt.pitch, tt.yaw, tt.roll, got, quat(want))
+		}
+	}
+}
+
+func TestQuatLook(t *testing.T) {
+	tests := []struct {
+		forward, up mgl32.Vec3
+	}{
+		{m3(0, 0, -1), m3(0, 1, 0)},
+		{m3(0, 0, 1), m3(0, 1, 0)},
+		{m3(1, 0, 0), m3(0, 1, 0)},
+		{m3(1, -2, 3), m3(0, 1, 0)},
+		{m3(0, -1, -1), m3(0, 0, 1)},
+	}
+
+	for _, tt := range tests {
+		q := QuatLook(Vec{tt.forward[0], tt.forward[1], tt.forward[2]}, Vec{tt.up[0], tt.up[1], tt.up[2]})
+
+		// the camera rotation is the inverse of the rotation of a view matrix
+		want := mgl32.LookAtV(mgl32.Vec3{}, tt.forward, tt.up).Transpose()
+		if got := q.Mat(); !matNear(got, want) {
+			t.Errorf("QuatLook(%v, %v).Mat() = %v, want %v", tt.forward, tt.up, got, Mat(want))
+		}
+
+		if got := q.Rotate(Vec{0, 0, -1}); !vecNear(got, tt.forward.Normalize()) {
+			t.Errorf("QuatLook(%v, %v) rotates -z onto %v", tt.forward, tt.up, got)
+		}
+	}
+}
+
+func TestQuatMat(t *testing.T) {
+	for name, want := range testQuats {
+		m := Mat(want.Mat4())
+
+		// round trip, QuatMat may return the negated quaternion
+		q := QuatMat(m)
+		if !sameRot(q, want) {
+			t.Errorf("%v: QuatMat(%v) = %v, want %v", name, m, q, quat(want))
+		}
+
+		if got := q.Mat(); !matNear(got, want.Mat4()) {
+			t.Errorf("%v: QuatMat(m).Mat() = %v, want %v", name, got, m)
+		}
+
+		if w := mgl32.Mat4ToQuat(want.Mat4()); !sameRot(q, w) {
+			t.Errorf("%v: QuatMat(%v) = %v, mgl32 %v", name, m, q, quat(w))
+		}
+
+		// translation is ignored
+		if got := QuatMat(Ident().Pos(Vec{1, 2, 3}).Mul(m)); got != q {
+			t.Errorf("%v: QuatMat with translation = %v, want %v", name, got, q)
+		}
+	}
+}
+
+func TestQuatMulInv(t *testing.T) {
+	for an, a := range testQuats {
+		for bn, b := range testQuats {
+			if got := quat(a).Mul(quat(b)); !quatNear(got, a.Mul(b)) {
+				t.Errorf("%v.Mul(%v) = %v, want %v", an, bn, got, quat(a.Mul(b)))
+			}
+
+			// the product applies b first
+			if got := quat(a).Mul(quat(b)).Mat(); !matNear(got, a.Mat4().Mul4(b.Mat4())) {
+				t.Errorf("%v.Mul(%v).Mat() = %v, want %v", an, bn, got, Mat(a.Mat4().Mul4(b.Mat4())))
+			}
+		}
+
+		if got := quat(a).Inv(); !quatNear(got, a.Inverse()) {
+			t.Errorf("%v.Inv() = %v, want %v", an, got, quat(a.Inverse()))
+		}
+
+		if got := quat(a).Mul(quat(a).Inv()); !quatNear(got, mgl32.QuatIdent()) {
+			t.Errorf("%v.Mul(Inv()) = %v, want identity", an, got)
+		}
+
+		// not normalized
+		s := a.Scale(2)
+		if got := quat(s).Inv(); !quatNear(got, s.Inverse()) {
+			t.Errorf("2*%v.Inv() = %v, want %v", an, got, quat(s.Inverse()))
+		}
+	}
+
+	if got := (Quat{}).Inv(); got != QuatIdent() {
+		t.Errorf("zero Inv() = %v, want identity", got)
+	}
+}
+
+func TestQuatSlerp(t *testing.T) {
+	for an, a := range testQuats {
+		for bn, b := range testQuats {
+			q, q2 := quat(a), quat(b)
+
+			if got := q.Slerp(q2, 0); !sameRot(got, a) {
+				t.Errorf("%v.Slerp(%v, 0) = %v, want %v", an, bn, got, q)
+			}
+
+			if got := q.Slerp(q2, 1); !sameRot(got, b) {
+				t.Errorf("%v.Slerp(%v, 1) = %v, want %v", an, bn, got, q2)
+			}
+
+			// mgl32 does not take the shortest path, so only compare when a
+			// and b are in the same hemisphere
+			if a.Dot(b) >= 0 {
+				for _, s := range []float32{0.25, 0.5, 0.8} {
+					if got, want := q.Slerp(q2, s), mgl32.QuatSlerp(a, b, s); !sameRot(got, want) {
+						t.Errorf("%v.Slerp(%v, %v) = %v, want %v", an, bn, s, got, quat(want))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestQuatSlerpShortest(t *testing.T) {
+	z := Vec{0, 0, 1}
+	a := QuatAxisAngle(z, 0.2)
+	b := QuatAxisAngle(z, 1.4)
+	neg := Quat{-b[0], -b[1], -b[2], -b[3]}
+
+	// -b is the same rotation as b, the interpolation must not take the long
+	// way around
+	for _, s := range []float32{0, 0.25, 0.5, 0.75, 1} {
+		want := mgl32.QuatRotate(0.2+1.2*s, m3(0, 0, 1))
+
+		if got := a.Slerp(b, s); !sameRot(got, want) {
+			t.Errorf("Slerp(b, %v) = %v, want %v", s, got, quat(want))
+		}
+
+		if got := a.Slerp(neg, s); !sameRot(got, want) {
+			t.Errorf("Slerp(-b, %v) = %v, want %v", s, got, quat(want))
+		}
+	}
+
+	// nearly parallel quaternions interpolate linearly and stay normalized
+	c := QuatAxisAngle(z, 0.2001)
+	if got := a.Slerp(c, 0.5); math.Abs(float64(got.Len()-1)) > mathEps {
+		t.Errorf("Slerp of nearly parallel quaternions has length %v", got.Len())
+	}
+}
+
+func TestQuatRotate(t *testing.T) {
+	points := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 2, 3}, {-4, 0.5, -2}}
+
+	for name, want := range testQuats {
+		q := quat(want)
+
+		for _, p := range points {
+			v := Vec{p[0], p[1], p[2], 1}
+
+			got := q.Rotate(v)
+			if !vecNear(got, want.Rotate(p)) {
+				t.Errorf("%v: Rotate(%v) = %v, want %v", name, v, got, want.Rotate(p))
+			}
+
+			if m := q.Mat().Transform(v); !vecNear(got, m3(m[0], m[1], m[2])) {
+				t.Errorf("%v: Rotate(%v) = %v, Mat().Transform = %v", name, v, got, m)
+			}
+
+			if got[3] != 1 {
+				t.Errorf("%v: Rotate(%v) changed w to %v", name, v, got[3])
+			}
+		}
+	}
+}
@@ -0,0 +1,17 @@
+#version 460 core
+
+// adds the blurred highlights to the input of the effect
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;  // blurred highlights
+uniform sampler2D orig; // input of the effect
+uniform float intensity;
+
+void main() {
+	vec4 c = texture(orig, uv);
+
+	out_color = vec4(c.rgb + texture(src, uv).rgb * intensity, c.a);
+}
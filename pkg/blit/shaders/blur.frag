@@ -0,0 +1,26 @@
+#version 460 core
+
+// one direction of a separable 9 tap gaussian blur
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+uniform vec2 texel;   // size of a texel in uv units
+uniform vec2 dir;     // (1, 0) for horizontal, (0, 1) for vertical
+uniform float radius; // spacing of the taps in texels
+
+const float weights[5] = float[](0.227027, 0.1945946, 0.1216216, 0.054054, 0.016216);
+
+void main() {
+	vec2 offset = dir * texel * radius;
+
+	vec4 c = texture(src, uv) * weights[0];
+	for (int i = 1; i < 5; i++) {
+		c += texture(src, uv + offset * float(i)) * weights[i];
+		c += texture(src, uv - offset * float(i)) * weights[i];
+	}
+
+	out_color = c;
+}
@@ -0,0 +1,17 @@
+#version 460 core
+
+// keeps the parts of the image brighter than a threshold
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+uniform float threshold;
+
+void main() {
+	vec4 c = texture(src, uv);
+	float l = dot(c.rgb, vec3(0.2126, 0.7152, 0.0722));
+
+	out_color = vec4(c.rgb * smoothstep(threshold, threshold + 0.1, l), 1.0);
+}
@@ -0,0 +1,11 @@
+#version 460 core
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+
+void main() {
+	out_color = texture(src, uv);
+}
@@ -0,0 +1,33 @@
+#version 460 core
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+uniform vec2 texel;
+uniform float time;
+uniform float curvature;  // barrel distortion of the screen
+uniform float scanlines;  // darkness of the scanlines
+uniform float flicker;    // brightness variation over time
+
+void main() {
+	// bend the screen outwards
+	vec2 p = uv * 2.0 - 1.0;
+	p *= 1.0 + curvature * dot(p.yx, p.yx);
+	vec2 st = p * 0.5 + 0.5;
+
+	if (st.x < 0.0 || st.x > 1.0 || st.y < 0.0 || st.y > 1.0) {
+		out_color = vec4(0.0, 0.0, 0.0, 1.0);
+		return;
+	}
+
+	vec4 c = texture(src, st);
+
+	// one dark line every other output row
+	float line = 0.5 + 0.5 * cos(st.y / texel.y * 3.14159265);
+	c.rgb *= 1.0 - scanlines * line;
+	c.rgb *= 1.0 - flicker * (0.5 + 0.5 * sin(time * 60.0));
+
+	out_color = c;
+}
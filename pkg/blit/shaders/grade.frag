@@ -0,0 +1,28 @@
+#version 460 core
+
+// color grading through a lookup table stored as a horizontal strip of size
+// slices of size x size texels, red along x, green along y, blue per slice
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+uniform sampler2D lut;
+uniform float size;   // lookup table size per channel
+uniform float amount; // 0 keeps the colors, 1 fully graded
+
+void main() {
+	vec4 c = texture(src, uv);
+	vec3 v = clamp(c.rgb, 0.0, 1.0) * (size - 1.0);
+
+	float b0 = floor(v.b);
+	float b1 = min(b0 + 1.0, size - 1.0);
+	vec2 cell = (v.rg + 0.5) / vec2(size * size, size);
+
+	vec3 g0 = texture(lut, cell + vec2(b0 / size, 0.0)).rgb;
+	vec3 g1 = texture(lut, cell + vec2(b1 / size, 0.0)).rgb;
+	vec3 graded = mix(g0, g1, v.b - b0);
+
+	out_color = vec4(mix(c.rgb, graded, amount), c.a);
+}
@@ -0,0 +1,15 @@
+#version 460 core
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+uniform float amount; // 0 keeps the colors, 1 is fully gray
+
+void main() {
+	vec4 c = texture(src, uv);
+	float l = dot(c.rgb, vec3(0.2126, 0.7152, 0.0722));
+
+	out_color = vec4(mix(c.rgb, vec3(l), amount), c.a);
+}
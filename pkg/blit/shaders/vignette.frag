@@ -0,0 +1,18 @@
+#version 460 core
+
+in vec2 uv;
+
+out vec4 out_color;
+
+uniform sampler2D src;
+uniform float radius;   // distance from the center where darkening starts
+uniform float softness; // width of the falloff
+uniform float strength; // darkness at the corners
+
+void main() {
+	vec4 c = texture(src, uv);
+	float d = length(uv - 0.5) * 1.41421356;
+	float v = smoothstep(radius, radius + softness, d);
+
+	out_color = vec4(c.rgb * (1.0 - v * strength), c.a);
+}
@@ -0,0 +1,185 @@
+package blit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SheetDesc is a decoded spritesheet description. In JSON it is an object
+// mapping sprite names to [x, y, w, h] rects, optionally followed by the trim
+// offset and untrimmed source size ([x, y, w, h, trim x, trim y, source w,
+// source h]). The reserved "grid" key holds a Grid descriptor.
+type SheetDesc struct {
+	Grid    *Grid
+	Sprites []SpriteDesc // in document order
+}
+
+// SpriteDesc is a single named sprite of a SheetDesc
+type SpriteDesc struct {
+	Name string
+	Rect Rect
+	Trim *Trim // nil if not trimmed
+}
+
+// ParseSheetDesc decodes a spritesheet description. Entries that cannot be
+// decoded are reported as errors and skipped; duplicate names are reported as
+// warnings and the last definition is kept.
+func ParseSheetDesc(data []byte) (SheetDesc, Diagnostics) {
+	var desc SheetDesc
+	var diags Diagnostics
+
+	keys, values, err := objectEntries(data)
+	if err != nil {
+		diags.errorf("", "invalid spritesheet json: %v", err)
+		return desc, diags
+	}
+
+	index := map[string]int{}
+	for i, name := range keys {
+		if name == "grid" {
+			if desc.Grid != nil {
+				diags.warnf(name, "duplicate grid, the last definition is used")
+			}
+
+			var g Grid
+			if err := json.Unmarshal(values[i], &g); err != nil {
+				diags.errorf(name, "invalid grid: %v", err)
+				continue
+			}
+
+			desc.Grid = &g
+			continue
+		}
+
+		s, err := parseSpriteDesc(name, values[i])
+		if err != nil {
+			diags.errorf(name, "%v", err)
+			continue
+		}
+
+		if j, ok := index[name]; ok {
+			diags.warnf(name, "duplicate sprite, the last definition is used")
+			desc.Sprites[j] = s
+			continue
+		}
+
+		index[name] = len(desc.Sprites)
+		desc.Sprites = append(desc.Sprites, s)
+	}
+
+	return desc, diags
+}
+
+// parseSpriteDesc decodes the rect array of a sprite
+func parseSpriteDesc(name string, raw json.RawMessage) (SpriteDesc, error) {
+	s := SpriteDesc{Name: name}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		return s, fmt.Errorf("expected an array, got %s", raw)
+	}
+
+	var v []float32
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return s, fmt.Errorf("expected an array of numbers: %v", err)
+	}
+
+	if len(v) != 4 && len(v) != 8 {
+		return s, fmt.Errorf("expected [x, y, w, h] or [x, y, w, h, trim x, trim y, source w, source h], got %v values", len(v))
+	}
+
+	s.Rect = Rect{v[0], v[1], v[2], v[3]}
+
+	if len(v) == 8 {
+		s.Trim = &Trim{
+			Offset: Vec{v[4], v[5]},
+			Size:   Vec{v[6], v[7]},
+		}
+	}
+
+	return s, nil
+}
+
+// Validate checks the description against an image of the given size.
+// Rects with a non-positive size or outside the image and invalid trims or
+// grids are errors; overlapping sprites and sprites overriding grid tiles are
+// warnings.
+func (d SheetDesc) Validate(w, h int) Diagnostics {
+	var diags Diagnostics
+
+	gridNames := map[string]bool{}
+	if d.Grid != nil {
+		g := *d.Grid
+		if err := g.fit(w, h); err != nil {
+			diags.errorf("grid", "%v", err)
+		} else {
+			for i := 0; i < g.Len(); i++ {
+				if name := g.Name(i); name != "" {
+					gridNames[name] = true
+				}
+			}
+		}
+	}
+
+	for _, s := range d.Sprites {
+		if gridNames[s.Name] {
+			diags.warnf(s.Name, "overrides a grid tile of the same name")
+		}
+	}
+
+	checkSprites(&diags, d.Sprites, w, h)
+
+	return diags
+}
+
+// checkSprites reports sprites whose rect or trim does not fit a w by h image
+// as errors and overlapping sprites as warnings
+func checkSprites(diags *Diagnostics, sprites []SpriteDesc, w, h int) {
+	var valid []SpriteDesc
+	for _, s := range sprites {
+		ok := checkRect(diags, s.Name, s.Rect, w, h)
+
+		if s.Trim != nil {
+			t := *s.Trim
+			if t.Offset[0] < 0 || t.Offset[1] < 0 ||
+				t.Offset[0]+s.Rect.W() > t.Size[0] || t.Offset[1]+s.Rect.H() > t.Size[1] {
+				diags.errorf(s.Name, "trimmed rect at (%v, %v) does not fit the %vx%v source", t.Offset[0], t.Offset[1], t.Size[0], t.Size[1])
+				ok = false
+			}
+		}
+
+		if ok {
+			valid = append(valid, s)
+		}
+	}
+
+	for i, a := range valid {
+		for _, b := range valid[i+1:] {
+			if rectsOverlap(a.Rect, b.Rect) {
+				diags.warnf(a.Name, "overlaps \"%v\"", b.Name)
+			}
+		}
+	}
+}
+
+// checkRect reports a rect with a non-positive size or outside a w by h image
+func checkRect(diags *Diagnostics, name string, r Rect, w, h int) bool {
+	if r.W() <= 0 || r.H() <= 0 {
+		diags.errorf(name, "rect %v has a non-positive size", r)
+		return false
+	}
+
+	if r.X() < 0 || r.Y() < 0 || r.X()+r.W() > float32(w) || r.Y()+r.H() > float32(h) {
+		diags.errorf(name, "rect %v is outside the %vx%v image", r, w, h)
+		return false
+	}
+
+	return true
+}
+
+// rectsOverlap reports whether two rects share any area
+func rectsOverlap(a, b Rect) bool {
+	return a.X() < b.X()+b.W() && b.X() < a.X()+a.W() &&
+		a.Y() < b.Y()+b.H() && b.Y() < a.Y()+a.H()
+}
@@ -42,7 +42,8 @@ func NewSprite(shader *bgl.Program, texture *bgl.Texture, rect Rect) (*Sprite, e
 		shader:  shader,
 		Tex:     texture,
 		Rect:    rect,
-		O:       &Orienter{},
+		O:       NewOrienter(),
+		Mask:    color.RGBA{255, 255, 255, 255},
 		Visible: true,
 	}
 
@@ -58,6 +59,16 @@ func (s *Sprite) Dirty() {
 	s.dirty = true
 }
 
+// SetRect sets the texture rectangle, updating the quad on the next draw
+func (s *Sprite) SetRect(r Rect) {
+	if s.Rect == r {
+		return
+	}
+
+	s.Rect = r
+	s.Dirty()
+}
+
 // Mat is a wrapper for s.O.Mat()
 func (s *Sprite) Mat() Mat {
 	return s.O.Mat()
@@ -94,8 +105,8 @@ func (s *Sprite) quad() []float32 {
 	return q
 }
 
-func (s *Sprite) mask() []float32 {
-	return []float32{
+func (s *Sprite) mask() [4]float32 {
+	return [4]float32{
 		float32(s.Mask.R) / 255.0,
 		float32(s.Mask.G) / 255.0,
 		float32(s.Mask.B) / 255.0,
@@ -103,12 +114,25 @@ func (s *Sprite) mask() []float32 {
 	}
 }
 
+// Draw draws the sprite using its own orienter
 func (s *Sprite) Draw() {
+	s.DrawMat(s.Mat())
+}
+
+// DrawMat draws the sprite with the given world transform, allowing sprites to
+// be attached to a Node
+func (s *Sprite) DrawMat(m Mat) {
 	if s.Visible {
+		if s.dirty {
+			s.vbo.SetData(s.quad())
+			s.vbo.Unbind()
+			s.dirty = false
+		}
+
 		s.shader.Bind()
 
-		s.shader.SetUniform("color", s.mask())
-		s.shader.SetUniform("modl", s.Mat().F())
+		s.shader.SetVec4f("color", s.mask())
+		s.shader.SetMat4f("modl", m.F())
 
 		s.Tex.Bind()
 		s.vao.Bind()
@@ -120,8 +144,3 @@ func (s *Sprite) Draw() {
 		s.shader.Unbind()
 	}
 }
-
-type Animation struct {
-	Frame  int
-	Frames []Sprite
-}
@@ -1,99 +1,187 @@
 package blit
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"image"
 	"image/draw"
-	"image/png"
-	"io/ioutil"
 
 	"github.com/octalide/blit/pkg/bgl"
 )
 
+// Trim describes where a trimmed sprite lies in its untrimmed source image
+type Trim struct {
+	Offset Vec // position of the trimmed rect in the source image (pixels)
+	Size   Vec // size of the untrimmed source image (pixels)
+}
+
+// Slice is a named region of the source image, optionally changing per frame
+type Slice struct {
+	Name  string
+	Color string // editor color as "#rrggbbaa"
+	Keys  []SliceKey
+}
+
+// SliceKey is the state of a slice from a frame onwards. Positions are in
+// source image pixels.
+type SliceKey struct {
+	Frame  int
+	Bounds Rect
+	Center Rect // 9-patch center relative to Bounds, zero if not set
+	Pivot  Vec  // pivot relative to Bounds
+}
+
+// At returns the key active at the given frame
+func (s Slice) At(frame int) SliceKey {
+	var key SliceKey
+	for _, k := range s.Keys {
+		if k.Frame > frame {
+			break
+		}
+		key = k
+	}
+
+	return key
+}
+
+// NinePatch reports whether the slice has a 9-patch center at the given frame
+func (s Slice) NinePatch(frame int) bool {
+	c := s.At(frame).Center
+
+	return c.W() > 0 && c.H() > 0
+}
+
 type Spritesheet struct {
 	Texture *bgl.Texture
 	Sprites map[string]Rect
 	Filter  bgl.Filter
+
+	Frames     []Frame               // frames in sheet order (Aseprite only)
+	Animations map[string]*Animation // named animations (Aseprite frame tags)
+	Slices     map[string]Slice      // named slices (Aseprite only)
+	Trims      map[string]Trim       // untrimmed source placement of trimmed sprites
+
+	Grid *Grid // tile grid, nil if the sheet is not grid based
+
+	Warnings Diagnostics // problems found while loading that did not prevent it
 }
 
 func NewSpritesheet() *Spritesheet {
 	ss := &Spritesheet{
-		Sprites: map[string]Rect{},
-		Filter:  bgl.Nearest,
+		Sprites:    map[string]Rect{},
+		Filter:     bgl.Nearest,
+		Animations: map[string]*Animation{},
+		Slices:     map[string]Slice{},
+		Trims:      map[string]Trim{},
 	}
 
 	return ss
 }
 
-func GenSpritesheet(img *image.RGBA, desc map[string]interface{}) (*Spritesheet, error) {
-	ss := NewSpritesheet()
+// GenSpritesheet creates a spritesheet from an image and its description. The
+// description is validated against the image first; all errors are returned
+// together as Diagnostics and warnings are kept in the spritesheet.
+// Explicit rects take precedence over grid names.
+func GenSpritesheet(img *image.RGBA, desc SheetDesc) (*Spritesheet, error) {
+	ss, err := genSpritesheet(desc, img.Bounds().Dx(), img.Bounds().Dy())
+	if err != nil {
+		return nil, err
+	}
 
-	for k, v := range desc {
-		raw := v.([]interface{})
+	ss.Texture = bgl.NewTexture(rebase(img), ss.Filter)
 
-		x, ok := raw[0].(float64)
-		if !ok {
-			return nil, fmt.Errorf("invalid x value for \"%v\"", k)
-		}
-		y, ok := raw[1].(float64)
-		if !ok {
-			return nil, fmt.Errorf("invalid y value for \"%v\"", k)
-		}
-		w, ok := raw[2].(float64)
-		if !ok {
-			return nil, fmt.Errorf("invalid w value for \"%v\"", k)
-		}
-		h, ok := raw[3].(float64)
-		if !ok {
-			return nil, fmt.Errorf("invalid h value for \"%v\"", k)
-		}
+	return ss, nil
+}
 
-		ss.Sprites[k] = Rect{
-			float32(x),
-			float32(y),
-			float32(w),
-			float32(h),
+// genSpritesheet creates a spritesheet without texture for an image of the
+// given size
+func genSpritesheet(desc SheetDesc, w, h int) (*Spritesheet, error) {
+	diags := desc.Validate(w, h)
+	if err := diags.Err(); err != nil {
+		return nil, err
+	}
+
+	ss := NewSpritesheet()
+	ss.Warnings = diags
+
+	if desc.Grid != nil {
+		if err := ss.setGrid(*desc.Grid, w, h); err != nil {
+			return nil, err
 		}
 	}
 
-	b := img.Bounds()
-	img = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
-	draw.Draw(img, img.Bounds(), img, b.Min, draw.Src)
+	for _, s := range desc.Sprites {
+		ss.Sprites[s.Name] = s.Rect
 
-	ss.Texture = bgl.NewTexture(img, ss.Filter)
+		if s.Trim != nil {
+			ss.Trims[s.Name] = *s.Trim
+		}
+	}
 
 	return ss, nil
 }
 
-func LoadSpritesheet(imgPath, descPath string) (*Spritesheet, error) {
-	raw, err := ioutil.ReadFile(imgPath)
-	if err != nil {
+// GenGridSpritesheet creates a spritesheet from an image split into a grid
+func GenGridSpritesheet(img *image.RGBA, g Grid) (*Spritesheet, error) {
+	ss := NewSpritesheet()
+
+	if err := ss.setGrid(g, img.Bounds().Dx(), img.Bounds().Dy()); err != nil {
 		return nil, err
 	}
 
-	img, err := png.Decode(bytes.NewReader(raw))
-	if err != nil {
-		return nil, err
+	ss.Texture = bgl.NewTexture(rebase(img), ss.Filter)
+
+	return ss, nil
+}
+
+// setGrid fits the grid to an image of the given size and adds its named
+// tiles to the sprites
+func (ss *Spritesheet) setGrid(g Grid, w, h int) error {
+	if err := g.fit(w, h); err != nil {
+		return err
 	}
 
-	desc, err := ioutil.ReadFile(descPath)
-	if err != nil {
-		return nil, err
+	for i := 0; i < g.Len(); i++ {
+		if name := g.Name(i); name != "" {
+			ss.Sprites[name] = g.Index(i)
+		}
 	}
 
-	var descMap map[string]interface{}
-	err = json.Unmarshal(desc, &descMap)
-	if err != nil {
-		return nil, err
+	ss.Grid = &g
+
+	return nil
+}
+
+// Index returns the rect of the grid tile at the given index. It reports false
+// if the sheet has no grid or the index is out of range.
+func (ss *Spritesheet) Index(i int) (Rect, bool) {
+	if ss.Grid == nil || i < 0 || i >= ss.Grid.Len() {
+		return Rect{}, false
+	}
+
+	return ss.Grid.Index(i), true
+}
+
+// Cell returns the rect of the grid tile at the given column and row. It
+// reports false if the sheet has no grid or the cell is out of range.
+func (ss *Spritesheet) Cell(col, row int) (Rect, bool) {
+	if ss.Grid == nil || col < 0 || row < 0 || col >= ss.Grid.Cols || row >= ss.Grid.Rows {
+		return Rect{}, false
+	}
+
+	return ss.Grid.Cell(col, row), true
+}
+
+// rebase returns the image with its bounds moved to the origin
+func rebase(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	if b.Min == (image.Point{}) {
+		return img
 	}
 
-	// convert to RGBA
-	rgba := image.NewRGBA(img.Bounds())
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
 
-	return GenSpritesheet(rgba, descMap)
+	return dst
 }
 
 func (ss *Spritesheet) Get(name string, shader *bgl.Program) (*Sprite, error) {
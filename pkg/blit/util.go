@@ -21,8 +21,8 @@ func Perspective(fov, aspect, near, far float32) Mat {
 	return Mat{
 		f / aspect, 0, 0, 0,
 		0, f, 0, 0,
-		0, 0, (far + near) / (near - far), 2 * far * near / (near - far),
-		0, 0, -1, 0,
+		0, 0, (far + near) / (near - far), -1,
+		0, 0, 2 * far * near / (near - far), 0,
 	}
 }
 
@@ -0,0 +1,92 @@
+package blit
+
+import (
+	"io/fs"
+	"time"
+)
+
+// Watcher polls files of a file system for modifications by comparing their
+// modification times and sizes. It needs no platform specific APIs but only
+// notices changes on file systems reporting modification times (os.DirFS does,
+// embed.FS does not).
+type Watcher struct {
+	FS       fs.FS
+	Interval time.Duration // minimum time between two polls
+
+	files map[string]*watchedFile
+	last  time.Time
+}
+
+type watchedFile struct {
+	refs    int
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a watcher polling fsys at most once per interval
+func NewWatcher(fsys fs.FS, interval time.Duration) *Watcher {
+	return &Watcher{
+		FS:       fsys,
+		Interval: interval,
+		files:    map[string]*watchedFile{},
+	}
+}
+
+// Add starts watching a file. Files added more than once must be removed as
+// many times.
+func (w *Watcher) Add(name string) {
+	if f, ok := w.files[name]; ok {
+		f.refs++
+		return
+	}
+
+	f := &watchedFile{refs: 1}
+	if info, err := fs.Stat(w.FS, name); err == nil {
+		f.modTime = info.ModTime()
+		f.size = info.Size()
+	}
+
+	w.files[name] = f
+}
+
+// Remove stops watching a file
+func (w *Watcher) Remove(name string) {
+	f, ok := w.files[name]
+	if !ok {
+		return
+	}
+
+	f.refs--
+	if f.refs <= 0 {
+		delete(w.files, name)
+	}
+}
+
+// Poll returns the files modified since the last poll. It returns nothing if
+// called again before the interval elapsed, so it can be called every frame.
+// Files that cannot be stat'ed (e.g. while being written) are skipped until
+// they reappear.
+func (w *Watcher) Poll() []string {
+	now := time.Now()
+	if now.Sub(w.last) < w.Interval {
+		return nil
+	}
+	w.last = now
+
+	var changed []string
+	for name, f := range w.files {
+		info, err := fs.Stat(w.FS, name)
+		if err != nil {
+			continue
+		}
+
+		if !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+			f.modTime = info.ModTime()
+			f.size = info.Size()
+
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
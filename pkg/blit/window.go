@@ -209,6 +209,12 @@ func (w *Window) SetVSync(vsync bool) {
 	}
 }
 
+// Screen converts a cursor position (origin top left) to window coordinates
+// (origin bottom left) as used by viewports and Cam.
+func (w *Window) Screen(cursor Vec) Vec {
+	return Vec{cursor[0], float32(w.GetHeight()) - cursor[1]}
+}
+
 func (w *Window) LockAspectRatio(numer, denom int) {
 	w.win.SetAspectRatio(numer, denom)
 }